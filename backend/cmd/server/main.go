@@ -10,6 +10,9 @@ import (
 
 	"my-app-backend/internal/db"
 	httpSrv "my-app-backend/internal/http"
+	appmw "my-app-backend/internal/middleware"
+	"my-app-backend/internal/seasons"
+	"my-app-backend/internal/webhooks"
 )
 
 func loadEnv() {
@@ -30,6 +33,7 @@ func loadEnv() {
 
 func main() {
 	loadEnv()
+	appmw.CheckTrustedProxies()
 
 	ctx := context.Background()
 	if err := db.Init(ctx); err != nil {
@@ -37,6 +41,11 @@ func main() {
 	}
 	defer db.Close() // << ใช้อันนี้เท่านั้น ไม่เรียก pool โดยตรง
 
+	// หมุน season ของ leaderboard (archive season ที่จบ + เปิด season ใหม่)
+	go seasons.Run(ctx)
+	// ส่ง webhook ที่ค้างในคิว (retry แบบ backoff)
+	go webhooks.Run(ctx)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
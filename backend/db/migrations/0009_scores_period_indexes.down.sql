@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_scores_created;
+DROP INDEX IF EXISTS idx_scores_gamename_created;
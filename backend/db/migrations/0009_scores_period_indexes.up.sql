@@ -0,0 +1,8 @@
+-- Leaderboard รายวัน/สัปดาห์/เดือน กรองด้วย created_at ก่อนแล้วค่อยเรียง score
+-- ช่วงเวลาสั้น ๆ จึงสแกนแค่แถวในช่วงนั้น ไม่ต้องไล่ทั้งตาราง
+CREATE INDEX IF NOT EXISTS idx_scores_gamename_created
+  ON public.scores (gamename, created_at);
+
+-- กรณีดูรวมทุกเกม (gamename = '')
+CREATE INDEX IF NOT EXISTS idx_scores_created
+  ON public.scores (created_at);
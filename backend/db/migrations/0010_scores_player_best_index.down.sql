@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_scores_gamename_name_score;
@@ -0,0 +1,4 @@
+-- ใช้กับ DISTINCT ON (gamename, name) ของ leaderboard แบบ best-per-player
+-- และประวัติคะแนนรายคน (gamename + name)
+CREATE INDEX IF NOT EXISTS idx_scores_gamename_name_score
+  ON public.scores (gamename, name, score DESC, created_at ASC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feedbacks_created_id_desc;
+DROP INDEX IF EXISTS idx_scores_gamename_score_created_id;
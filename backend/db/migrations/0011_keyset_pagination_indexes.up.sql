@@ -0,0 +1,6 @@
+-- keyset pagination ต้องมี id เป็นตัวตัดสินสุดท้ายให้ลำดับคงที่
+CREATE INDEX IF NOT EXISTS idx_scores_gamename_score_created_id
+  ON public.scores (gamename, score DESC, created_at ASC, id ASC);
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_created_id_desc
+  ON public.feedbacks (created_at DESC, id DESC);
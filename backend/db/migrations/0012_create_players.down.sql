@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_quiz_runs_player_created;
+DROP TABLE IF EXISTS public.quiz_runs;
+DROP INDEX IF EXISTS idx_scores_player;
+ALTER TABLE public.scores DROP COLUMN IF EXISTS player_id;
+DROP INDEX IF EXISTS uq_players_display_name;
+DROP TABLE IF EXISTS public.players;
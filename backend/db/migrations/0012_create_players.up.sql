@@ -0,0 +1,38 @@
+-- ผู้เล่น (เริ่มจาก guest: ได้ id + device token ไม่ต้องสมัคร)
+CREATE TABLE IF NOT EXISTS public.players (
+  id           TEXT PRIMARY KEY CHECK (length(id) BETWEEN 16 AND 64),
+  display_name TEXT NOT NULL CHECK (length(display_name) BETWEEN 1 AND 64),
+  kind         TEXT NOT NULL DEFAULT 'guest' CHECK (kind IN ('guest')),
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- ชื่อที่แสดงเป็นของ player คนเดียว (ไม่สนตัวพิมพ์เล็กใหญ่)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_players_display_name
+  ON public.players (lower(display_name));
+
+-- คะแนนผูกกับ player (แถวเก่าไม่มี player_id)
+ALTER TABLE public.scores
+  ADD COLUMN IF NOT EXISTS player_id TEXT REFERENCES public.players(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scores_player
+  ON public.scores (player_id, gamename, created_at DESC)
+  WHERE player_id IS NOT NULL;
+
+-- ทุกครั้งที่ player ที่ระบุตัวตนได้ขอคำถาม (id = quiz id ใน token)
+CREATE TABLE IF NOT EXISTS public.quiz_runs (
+  id         TEXT PRIMARY KEY,
+  player_id  TEXT NOT NULL REFERENCES public.players(id) ON DELETE CASCADE,
+  quiz_id    BIGINT REFERENCES public.quizzes(id) ON DELETE SET NULL,
+  mode       TEXT NOT NULL,
+  tier       INT  NOT NULL,
+  category   TEXT NOT NULL DEFAULT '',
+  seconds    INT  NOT NULL,
+  hints_used INT  NOT NULL DEFAULT 0,
+  solved     BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  solved_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_runs_player_created
+  ON public.quiz_runs (player_id, created_at DESC);
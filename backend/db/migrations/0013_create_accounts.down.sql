@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_sessions_player;
+DROP TABLE IF EXISTS public.sessions;
+DROP INDEX IF EXISTS uq_accounts_username;
+DROP TABLE IF EXISTS public.accounts;
+DELETE FROM public.players WHERE kind = 'registered';
+ALTER TABLE public.players DROP CONSTRAINT IF EXISTS players_kind_check;
+ALTER TABLE public.players
+  ADD CONSTRAINT players_kind_check CHECK (kind IN ('guest'));
@@ -0,0 +1,31 @@
+-- บัญชีที่สมัครด้วย username/password (player ตัวเดียวกับ guest แค่ kind ต่างกัน)
+ALTER TABLE public.players DROP CONSTRAINT IF EXISTS players_kind_check;
+ALTER TABLE public.players
+  ADD CONSTRAINT players_kind_check CHECK (kind IN ('guest', 'registered'));
+
+CREATE TABLE IF NOT EXISTS public.accounts (
+  player_id     TEXT PRIMARY KEY REFERENCES public.players(id) ON DELETE CASCADE,
+  username      TEXT NOT NULL CHECK (length(username) BETWEEN 3 AND 32),
+  password_hash TEXT NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_accounts_username
+  ON public.accounts (lower(username));
+
+-- login session หนึ่งแถวต่อหนึ่งเครื่อง refresh token หมุนทุกครั้งที่ใช้
+CREATE TABLE IF NOT EXISTS public.sessions (
+  id           TEXT PRIMARY KEY,
+  player_id    TEXT NOT NULL REFERENCES public.players(id) ON DELETE CASCADE,
+  refresh_hash TEXT NOT NULL,
+  user_agent   TEXT NOT NULL DEFAULT '',
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at   TIMESTAMPTZ NOT NULL,
+  revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_player
+  ON public.sessions (player_id)
+  WHERE revoked_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_player_merges_guest;
+DROP TABLE IF EXISTS public.player_merges;
@@ -0,0 +1,14 @@
+-- บันทึกการรวม guest เข้ากับบัญชี (guest ถูกลบไปแล้ว จึงไม่มี FK)
+CREATE TABLE IF NOT EXISTS public.player_merges (
+  id                 BIGSERIAL PRIMARY KEY,
+  guest_id           TEXT NOT NULL,
+  account_id         TEXT NOT NULL REFERENCES public.players(id) ON DELETE CASCADE,
+  guest_name         TEXT NOT NULL,
+  scores_moved       INTEGER NOT NULL DEFAULT 0,
+  duplicates_removed INTEGER NOT NULL DEFAULT 0,
+  quiz_runs_moved    INTEGER NOT NULL DEFAULT 0,
+  merged_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_player_merges_guest
+  ON public.player_merges (guest_id);
@@ -0,0 +1,19 @@
+-- ข้อความที่ตัวกรองคำหยาบเจอ แต่ปล่อยผ่านไว้ให้ admin ตรวจ (action = flag)
+CREATE TABLE IF NOT EXISTS public.moderation_flags (
+  id          BIGSERIAL PRIMARY KEY,
+  field       TEXT NOT NULL,               -- name | message
+  source      TEXT NOT NULL,               -- endpoint ที่เจอ เช่น feedback, score, room
+  content     TEXT NOT NULL,
+  terms       TEXT[] NOT NULL DEFAULT '{}',
+  action      TEXT NOT NULL,
+  player_id   TEXT REFERENCES public.players(id) ON DELETE SET NULL,
+  ip          TEXT NOT NULL DEFAULT '',
+  status      TEXT NOT NULL DEFAULT 'pending'
+              CHECK (status IN ('pending', 'approved', 'rejected')),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_flags_pending
+  ON public.moderation_flags (created_at DESC)
+  WHERE status = 'pending';
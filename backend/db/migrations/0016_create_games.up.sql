@@ -0,0 +1,21 @@
+-- ทะเบียนเกม: gamename ใน scores ต้องเป็น id ที่มีในตารางนี้ (ตรวจตอนบันทึก)
+-- score_order: desc = มากดีกว่า, asc = น้อยดีกว่า (เช่น เวลาที่ใช้)
+CREATE TABLE IF NOT EXISTS public.games (
+  id               TEXT PRIMARY KEY CHECK (id ~ '^[A-Za-z0-9_-]{1,64}$'),
+  display_name     TEXT NOT NULL,
+  min_score        INTEGER NOT NULL DEFAULT 0,
+  max_score        INTEGER NOT NULL DEFAULT 1000000,
+  score_order      TEXT NOT NULL DEFAULT 'desc' CHECK (score_order IN ('desc', 'asc')),
+  requires_receipt BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (min_score <= max_score)
+);
+
+-- เกมที่ frontend ใช้อยู่ (ชื่อเดิมทั้งหมด คะแนนเก่าจะได้อยู่ leaderboard เดิม)
+INSERT INTO public.games (id, display_name, min_score, max_score, score_order, requires_receipt) VALUES
+  ('PolaJigsaw',     'Pola Jigsaw',        0, 1000000, 'desc', FALSE),
+  ('CatGame',        'Cat Game',           0, 1000000, 'desc', FALSE),
+  ('DogPuzzle',      'Dog Puzzle',         0, 1000000, 'desc', FALSE),
+  ('dog_question',   'Dog Question',       0, 1000000, 'desc', FALSE),
+  ('DogPuzzleParty', 'Dog Puzzle (Party)', 0, 1000,    'desc', TRUE)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.idx_quiz_runs_player_solved;
+DROP TABLE IF EXISTS public.player_badges;
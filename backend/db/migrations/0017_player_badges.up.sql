@@ -0,0 +1,12 @@
+-- badge ที่ผู้เล่นปลดล็อกแล้ว (นิยาม badge อยู่ในโค้ด internal/achievements)
+CREATE TABLE IF NOT EXISTS public.player_badges (
+  player_id   TEXT NOT NULL REFERENCES public.players(id) ON DELETE CASCADE,
+  badge_id    TEXT NOT NULL,
+  unlocked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (player_id, badge_id)
+);
+
+-- สำหรับนับวันที่เล่นติดกัน (streak) และคำที่ตอบถูกโดยไม่ใช้ hint
+CREATE INDEX IF NOT EXISTS idx_quiz_runs_player_solved
+  ON public.quiz_runs (player_id, solved_at)
+  WHERE solved;
@@ -0,0 +1,12 @@
+-- ผลเกม party ของผู้เล่นที่ระบุตัวตนได้ (หนึ่งแถวต่อคนต่อเกม)
+CREATE TABLE IF NOT EXISTS public.party_results (
+  id          BIGSERIAL PRIMARY KEY,
+  room_code   TEXT NOT NULL,
+  player_id   TEXT NOT NULL REFERENCES public.players(id) ON DELETE CASCADE,
+  score       INTEGER NOT NULL DEFAULT 0,
+  won         BOOLEAN NOT NULL DEFAULT FALSE,
+  finished_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_party_results_player
+  ON public.party_results (player_id, finished_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS public.season_standings;
+DROP INDEX IF EXISTS public.idx_scores_season_game_score;
+ALTER TABLE public.scores DROP COLUMN IF EXISTS season_id;
+DROP TABLE IF EXISTS public.seasons;
@@ -0,0 +1,46 @@
+-- season ของ leaderboard: ช่วง [starts_at, ends_at) ห้ามทับกัน
+-- season ใหม่สร้างอัตโนมัติโดย rollover job (internal/seasons)
+CREATE TABLE IF NOT EXISTS public.seasons (
+  id          BIGSERIAL PRIMARY KEY,
+  name        TEXT NOT NULL,
+  starts_at   TIMESTAMPTZ NOT NULL,
+  ends_at     TIMESTAMPTZ NOT NULL,
+  archived_at TIMESTAMPTZ,             -- เก็บอันดับสุดท้ายลง season_standings แล้ว
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seasons_range ON public.seasons (starts_at, ends_at);
+
+ALTER TABLE public.scores
+  ADD COLUMN IF NOT EXISTS season_id BIGINT REFERENCES public.seasons(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scores_season_game_score
+  ON public.scores (season_id, gamename, score DESC, created_at ASC, id ASC);
+
+-- คะแนนที่มีอยู่ก่อนมี season ทั้งหมดเป็น "Season 0" ซึ่งจบทันที
+-- (job จะ archive ให้ แล้วเปิด season ใหม่ที่ทุกคนเริ่มจากศูนย์)
+INSERT INTO public.seasons (name, starts_at, ends_at)
+SELECT 'Season 0', LEAST(COALESCE(min(created_at), now()), now() - interval '1 second'), now()
+FROM public.scores
+WHERE NOT EXISTS (SELECT 1 FROM public.seasons);
+
+UPDATE public.scores SET season_id = (SELECT min(id) FROM public.seasons)
+WHERE season_id IS NULL;
+
+-- อันดับสุดท้ายของแต่ละ season (คะแนนดีที่สุดต่อชื่อต่อเกม)
+CREATE TABLE IF NOT EXISTS public.season_standings (
+  season_id   BIGINT NOT NULL REFERENCES public.seasons(id) ON DELETE CASCADE,
+  gamename    TEXT NOT NULL,
+  position    INTEGER NOT NULL,  -- ลำดับไม่ซ้ำ (เท่ากันเอาคนที่ทำได้ก่อน)
+  rank        INTEGER NOT NULL,  -- คะแนนเท่ากันได้อันดับเดียวกัน
+  name        TEXT NOT NULL,
+  player_id   TEXT REFERENCES public.players(id) ON DELETE SET NULL,
+  score       INTEGER NOT NULL,
+  achieved_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (season_id, gamename, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_standings_player
+  ON public.season_standings (player_id)
+  WHERE player_id IS NOT NULL;
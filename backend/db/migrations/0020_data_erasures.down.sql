@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.data_erasures;
+DROP INDEX IF EXISTS public.idx_feedbacks_player;
+ALTER TABLE public.feedbacks DROP COLUMN IF EXISTS player_id;
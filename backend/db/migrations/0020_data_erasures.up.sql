@@ -0,0 +1,19 @@
+-- feedback ที่ส่งพร้อม token ผูกกับ player (ใช้ตอน export/ลบข้อมูลส่วนตัว)
+ALTER TABLE public.feedbacks
+  ADD COLUMN IF NOT EXISTS player_id TEXT REFERENCES public.players(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_player
+  ON public.feedbacks (player_id)
+  WHERE player_id IS NOT NULL;
+
+-- บันทึกการลบข้อมูลส่วนตัว (ไม่เก็บข้อมูลของผู้เล่นไว้อีก มีแค่ id กับจำนวนแถว)
+CREATE TABLE IF NOT EXISTS public.data_erasures (
+  id         BIGSERIAL PRIMARY KEY,
+  player_id  TEXT NOT NULL,
+  actor      TEXT NOT NULL,                 -- player id ตัวเอง หรือชื่อ admin
+  actor_kind TEXT NOT NULL CHECK (actor_kind IN ('player', 'admin')),
+  counts     JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_erasures_player ON public.data_erasures (player_id);
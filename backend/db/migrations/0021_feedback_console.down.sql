@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS public.feedback_notes;
+DROP INDEX IF EXISTS public.idx_feedbacks_tags;
+DROP INDEX IF EXISTS public.idx_feedbacks_status_created_id;
+DROP INDEX IF EXISTS public.idx_feedbacks_published_created_id;
+ALTER TABLE public.feedbacks
+  DROP COLUMN IF EXISTS updated_at,
+  DROP COLUMN IF EXISTS published,
+  DROP COLUMN IF EXISTS tags,
+  DROP COLUMN IF EXISTS status;
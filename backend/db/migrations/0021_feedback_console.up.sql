@@ -0,0 +1,29 @@
+-- workflow ของ feedback สำหรับ admin: new → triaged → resolved (หรือ spam)
+ALTER TABLE public.feedbacks
+  ADD COLUMN IF NOT EXISTS status     TEXT NOT NULL DEFAULT 'new'
+    CHECK (status IN ('new', 'triaged', 'resolved', 'spam')),
+  ADD COLUMN IF NOT EXISTS tags       TEXT[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS published  BOOLEAN NOT NULL DEFAULT FALSE, -- admin อนุมัติให้แสดงใน GET /api/feedback
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_published_created_id
+  ON public.feedbacks (created_at DESC, id DESC)
+  WHERE published;
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_status_created_id
+  ON public.feedbacks (status, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_tags ON public.feedbacks USING GIN (tags);
+
+-- บันทึกภายในของ admin (kind = status เก็บการเปลี่ยนสถานะอัตโนมัติ)
+CREATE TABLE IF NOT EXISTS public.feedback_notes (
+  id          BIGSERIAL PRIMARY KEY,
+  feedback_id BIGINT NOT NULL REFERENCES public.feedbacks(id) ON DELETE CASCADE,
+  kind        TEXT NOT NULL DEFAULT 'note' CHECK (kind IN ('note', 'status')),
+  author      TEXT NOT NULL,
+  body        TEXT NOT NULL CHECK (length(body) BETWEEN 1 AND 4000),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_notes_feedback
+  ON public.feedback_notes (feedback_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.feedback_rejections;
+DROP INDEX IF EXISTS public.idx_feedbacks_message_hash;
+ALTER TABLE public.feedbacks DROP COLUMN IF EXISTS message_hash;
@@ -0,0 +1,22 @@
+-- hash ของข้อความที่ normalize แล้ว ใช้หาข้อความซ้ำในช่วงเวลาสั้น ๆ
+ALTER TABLE public.feedbacks ADD COLUMN IF NOT EXISTS message_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_message_hash
+  ON public.feedbacks (message_hash, created_at DESC)
+  WHERE message_hash <> '';
+
+-- feedback ที่ถูกปฏิเสธโดยตัวกันสแปม (admin ตรวจดูได้ที่ /api/admin/feedback/rejections)
+CREATE TABLE IF NOT EXISTS public.feedback_rejections (
+  id         BIGSERIAL PRIMARY KEY,
+  reason     TEXT NOT NULL,
+  ip         TEXT NOT NULL DEFAULT '',
+  player_id  TEXT REFERENCES public.players(id) ON DELETE SET NULL,
+  name       TEXT NOT NULL DEFAULT '',
+  contact    TEXT NOT NULL DEFAULT '',
+  message    TEXT NOT NULL DEFAULT '',
+  source     TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_rejections_created_id
+  ON public.feedback_rejections (created_at DESC, id DESC);
@@ -0,0 +1,15 @@
+-- รูปประกอบ feedback (ไฟล์อยู่ใน storage ตาม storage_key, ตารางนี้เก็บแค่ข้อมูลกำกับ)
+CREATE TABLE IF NOT EXISTS public.feedback_attachments (
+  id           BIGSERIAL PRIMARY KEY,
+  feedback_id  BIGINT NOT NULL REFERENCES public.feedbacks(id) ON DELETE CASCADE,
+  storage_key  TEXT NOT NULL,
+  thumb_key    TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size_bytes   BIGINT NOT NULL,
+  width        INT NOT NULL,
+  height       INT NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_attachments_feedback
+  ON public.feedback_attachments (feedback_id, id);
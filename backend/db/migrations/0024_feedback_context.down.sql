@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.idx_feedbacks_request_id;
+ALTER TABLE public.feedbacks DROP COLUMN IF EXISTS context;
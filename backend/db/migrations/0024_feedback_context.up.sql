@@ -0,0 +1,7 @@
+-- ข้อมูลประกอบการตรวจสอบ (request id, user agent, เวอร์ชันแอป, ห้อง/โจทย์ที่เล่นอยู่ ฯลฯ)
+ALTER TABLE public.feedbacks ADD COLUMN IF NOT EXISTS context JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- หา feedback จาก request id ใน log
+CREATE INDEX IF NOT EXISTS idx_feedbacks_request_id
+  ON public.feedbacks ((context->>'request_id'))
+  WHERE context ? 'request_id';
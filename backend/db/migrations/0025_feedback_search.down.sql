@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.idx_feedbacks_search_trgm;
+DROP INDEX IF EXISTS public.idx_feedbacks_search_tsv;
+ALTER TABLE public.feedbacks DROP COLUMN IF EXISTS search_tsv;
+-- pg_trgm ปล่อยไว้ (อาจมีอย่างอื่นใช้)
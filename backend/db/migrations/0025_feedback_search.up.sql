@@ -0,0 +1,11 @@
+-- ค้นหา feedback: tsvector (อังกฤษ ตัดคำ/stem ได้) + trigram (ไทยไม่มีช่องว่างระหว่างคำ ใช้ค้นแบบ substring)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE public.feedbacks ADD COLUMN IF NOT EXISTS search_tsv tsvector
+  GENERATED ALWAYS AS (to_tsvector('english'::regconfig, coalesce(name, '') || ' ' || coalesce(message, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_search_tsv
+  ON public.feedbacks USING GIN (search_tsv);
+
+CREATE INDEX IF NOT EXISTS idx_feedbacks_search_trgm
+  ON public.feedbacks USING GIN ((name || ' ' || message) gin_trgm_ops);
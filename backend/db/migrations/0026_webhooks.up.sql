@@ -0,0 +1,53 @@
+-- ปลายทาง webhook ที่ admin ตั้งไว้ (events ว่าง = ไม่รับอะไร, '*' = ทุก event)
+CREATE TABLE IF NOT EXISTS public.webhook_endpoints (
+  id          BIGSERIAL PRIMARY KEY,
+  url         TEXT NOT NULL,
+  secret      TEXT NOT NULL,
+  events      TEXT[] NOT NULL DEFAULT '{}',
+  description TEXT NOT NULL DEFAULT '',
+  active      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- คิวส่ง: หนึ่งแถวต่อ event ต่อ endpoint
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+  id              BIGSERIAL PRIMARY KEY,
+  endpoint_id     BIGINT NOT NULL REFERENCES public.webhook_endpoints(id) ON DELETE CASCADE,
+  event           TEXT NOT NULL,
+  payload         JSONB NOT NULL,
+  player_id       TEXT, -- เจ้าของข้อมูลใน payload (ใช้ตอนลบข้อมูลส่วนบุคคล) ไม่ผูก FK
+  status          TEXT NOT NULL DEFAULT 'pending'
+                  CHECK (status IN ('pending', 'delivered', 'failed')),
+  attempts        INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_status     INT NOT NULL DEFAULT 0,
+  last_error      TEXT NOT NULL DEFAULT '',
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+  ON public.webhook_deliveries (next_attempt_at)
+  WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint
+  ON public.webhook_deliveries (endpoint_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_player
+  ON public.webhook_deliveries (player_id)
+  WHERE player_id IS NOT NULL;
+
+-- log การส่งแต่ละครั้ง
+CREATE TABLE IF NOT EXISTS public.webhook_attempts (
+  id          BIGSERIAL PRIMARY KEY,
+  delivery_id BIGINT NOT NULL REFERENCES public.webhook_deliveries(id) ON DELETE CASCADE,
+  attempt     INT NOT NULL,
+  status_code INT NOT NULL DEFAULT 0, -- 0 = ต่อไม่ติด/timeout
+  error       TEXT NOT NULL DEFAULT '',
+  duration_ms INT NOT NULL DEFAULT 0,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery
+  ON public.webhook_attempts (delivery_id, attempt);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.chat_messages;
+DROP TABLE IF EXISTS public.chat_conversations;
@@ -0,0 +1,24 @@
+-- บทสนทนากับ AI ของผู้เล่น (use_case เลือก system prompt ดู llm.SystemPromptFor)
+CREATE TABLE IF NOT EXISTS public.chat_conversations (
+  id         BIGSERIAL PRIMARY KEY,
+  player_id  TEXT NOT NULL REFERENCES public.players(id) ON DELETE CASCADE,
+  use_case   TEXT NOT NULL DEFAULT 'general',
+  title      TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_conversations_player
+  ON public.chat_conversations (player_id, created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS public.chat_messages (
+  id              BIGSERIAL PRIMARY KEY,
+  conversation_id BIGINT NOT NULL REFERENCES public.chat_conversations(id) ON DELETE CASCADE,
+  role            TEXT NOT NULL CHECK (role IN ('user', 'assistant')),
+  content         TEXT NOT NULL,
+  tokens          INT NOT NULL DEFAULT 0, -- ตามที่ provider บอก (หรือประมาณ) ใช้ตัด context
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_conversation
+  ON public.chat_messages (conversation_id, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE public.quiz_runs DROP COLUMN IF EXISTS ai_questions;
+ALTER TABLE public.quizzes DROP COLUMN IF EXISTS aliases;
@@ -0,0 +1,7 @@
+-- คำที่นับว่าเป็นคำตอบด้วย (ใช้กันผู้ช่วย AI เฉลย เช่น แมว → เหมียว, cat)
+ALTER TABLE public.quizzes
+  ADD COLUMN IF NOT EXISTS aliases TEXT[] NOT NULL DEFAULT '{}';
+
+-- จำนวนคำถามที่ถามผู้ช่วย AI ในคำนี้ (นับเป็นการใช้ hint ด้วย)
+ALTER TABLE public.quiz_runs
+  ADD COLUMN IF NOT EXISTS ai_questions INT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.llm_usage_daily;
@@ -0,0 +1,13 @@
+-- การใช้ LLM รวมต่อวัน (วันตามเวลาไทย) ใช้คุมงบรายวันและให้ admin ดู
+CREATE TABLE IF NOT EXISTS public.llm_usage_daily (
+  day           DATE NOT NULL,
+  provider      TEXT NOT NULL,
+  model         TEXT NOT NULL DEFAULT '',
+  use_case      TEXT NOT NULL DEFAULT '',
+  requests      INT NOT NULL DEFAULT 0,
+  input_tokens  BIGINT NOT NULL DEFAULT 0,
+  output_tokens BIGINT NOT NULL DEFAULT 0,
+  cost_usd      DOUBLE PRECISION NOT NULL DEFAULT 0, -- ตามราคาใน env ตอนที่เรียก
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (day, provider, model, use_case)
+);
@@ -0,0 +1 @@
+ALTER TABLE public.feedbacks DROP COLUMN IF EXISTS ip;
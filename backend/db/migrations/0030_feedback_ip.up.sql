@@ -0,0 +1,2 @@
+-- IP ของผู้ส่ง ใช้จำกัดการตรวจข้อความซ้ำให้อยู่ในผู้ส่งคนเดียวกันเมื่อไม่มี player id
+ALTER TABLE public.feedbacks ADD COLUMN IF NOT EXISTS ip TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.idx_scores_no_season;
@@ -0,0 +1,4 @@
+-- rollover job ใส่ season ให้คะแนนที่บันทึกตอนไม่มี season ปัจจุบันทุกรอบ
+CREATE INDEX IF NOT EXISTS idx_scores_no_season
+  ON public.scores (created_at)
+  WHERE season_id IS NULL;
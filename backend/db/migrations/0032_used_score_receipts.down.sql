@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.used_score_receipts;
@@ -0,0 +1,10 @@
+-- nonce ของ score receipt ที่ใช้ไปแล้ว (ใช้ได้ครั้งเดียว แม้ restart หรือมีหลาย instance)
+-- เก็บถึง expires_at แล้วลบทิ้ง (หลังนั้น receipt หมดอายุเองอยู่แล้ว)
+CREATE TABLE IF NOT EXISTS public.used_score_receipts (
+  nonce      TEXT PRIMARY KEY,
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_used_score_receipts_expires
+  ON public.used_score_receipts (expires_at);
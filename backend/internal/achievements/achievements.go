@@ -0,0 +1,163 @@
+// internal/achievements/achievements.go
+package achievements
+
+import (
+	"context"
+	"sync"
+
+	"my-app-backend/internal/db"
+)
+
+// Kind คือชนิดของ event ที่ handler ส่งเข้ามา
+type Kind string
+
+const (
+	QuizSolved Kind = "quiz_solved" // ตอบคำถามเดี่ยวถูก (POST /api/quiz/check)
+	ScoreSaved Kind = "score_saved" // บันทึกคะแนน (POST /api/scores)
+	PartyWon   Kind = "party_won"   // ชนะเกม party
+)
+
+// Event: PlayerID ว่าง = ไม่ระบุตัวตน (ไม่มี badge)
+type Event struct {
+	Kind     Kind
+	PlayerID string
+	Name     string // ชื่อที่แสดง
+
+	// ScoreSaved
+	Game       string
+	ScoreOrder string
+	Score      int
+
+	// PartyWon (และ event อื่นที่เกิดในห้อง)
+	RoomCode string
+}
+
+type Badge struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Timezone ของ "วัน" ใน streak ให้ตรงกับ leaderboard รายวัน
+const Timezone = "Asia/Bangkok"
+
+type rule struct {
+	badge Badge
+	on    Kind
+	check func(ctx context.Context, ev Event) (bool, error)
+}
+
+func always(context.Context, Event) (bool, error) { return true, nil }
+
+func streakAtLeast(n int) func(context.Context, Event) (bool, error) {
+	return func(ctx context.Context, ev Event) (bool, error) {
+		cur, _, err := db.SolveStreaks(ctx, ev.PlayerID, Timezone)
+		return cur >= n, err
+	}
+}
+
+var rules = []rule{
+	{Badge{"first_word", "คำแรก", "ตอบคำถามถูกเป็นครั้งแรก"}, QuizSolved, always},
+	{Badge{"no_hint_10", "ไม่ง้อคำใบ้", "ตอบถูก 10 คำโดยไม่ใช้ hint"}, QuizSolved,
+		func(ctx context.Context, ev Event) (bool, error) {
+			n, err := db.CountSolvedWithoutHints(ctx, ev.PlayerID)
+			return n >= 10, err
+		}},
+	{Badge{"streak_3", "มาทุกวัน", "ตอบถูกอย่างน้อยวันละคำ 3 วันติด"}, QuizSolved, streakAtLeast(3)},
+	{Badge{"streak_7", "ครบสัปดาห์", "ตอบถูกอย่างน้อยวันละคำ 7 วันติด"}, QuizSolved, streakAtLeast(7)},
+	{Badge{"top_10", "ท็อปเทน", "ติด 10 อันดับแรกของ leaderboard ตลอดกาล"}, ScoreSaved,
+		func(ctx context.Context, ev Event) (bool, error) {
+			rows, err := db.GetScoreRank(ctx, ev.Game, ev.ScoreOrder, ev.Name, db.TimeRange{}, 0)
+			if err != nil || len(rows) == 0 {
+				return false, err
+			}
+			return rows[0].Rank <= 10, nil
+		}},
+	{Badge{"first_party_win", "แชมป์ปาร์ตี้", "ชนะเกม party เป็นครั้งแรก"}, PartyWon, always},
+}
+
+// Catalog คืน badge ทั้งหมดที่มี
+func Catalog() []Badge {
+	out := make([]Badge, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, r.badge)
+	}
+	return out
+}
+
+func Lookup(id string) (Badge, bool) {
+	for _, r := range rules {
+		if r.badge.ID == id {
+			return r.badge, true
+		}
+	}
+	return Badge{}, false
+}
+
+// Notifier ถูกเรียกทุกครั้งที่ปลดล็อก badge ใหม่ (handlers ใช้ push ทาง WS ของห้อง)
+type Notifier func(ev Event, b Badge)
+
+var (
+	notifyMu sync.RWMutex
+	notify   Notifier
+)
+
+func SetNotifier(n Notifier) {
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+	notify = n
+}
+
+// Emit ตรวจกฎของ event นี้ แล้วคืน badge ที่เพิ่งปลดล็อก
+// กฎที่ error จะข้ามไป (คืน error แรกที่เจอ) ไม่ให้ทำให้กฎอื่นไม่ถูกตรวจ
+func Emit(ctx context.Context, ev Event) ([]Badge, error) {
+	if ev.PlayerID == "" {
+		return nil, nil
+	}
+	var unlocked []Badge
+	var firstErr error
+	keep := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, r := range rules {
+		if r.on != ev.Kind {
+			continue
+		}
+		has, err := db.HasBadge(ctx, ev.PlayerID, r.badge.ID)
+		if err != nil {
+			keep(err)
+			continue
+		}
+		if has {
+			continue
+		}
+		ok, err := r.check(ctx, ev)
+		if err != nil {
+			keep(err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		isNew, err := db.UnlockBadge(ctx, ev.PlayerID, r.badge.ID)
+		if err != nil {
+			keep(err)
+			continue
+		}
+		if isNew {
+			unlocked = append(unlocked, r.badge)
+		}
+	}
+
+	notifyMu.RLock()
+	n := notify
+	notifyMu.RUnlock()
+	if n != nil {
+		for _, b := range unlocked {
+			n(ev, b)
+		}
+	}
+	return unlocked, firstErr
+}
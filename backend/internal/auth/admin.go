@@ -0,0 +1,44 @@
+// internal/auth/admin.go
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"os"
+	"strings"
+)
+
+// AdminKeyHeader: admin ส่ง API key มาทาง header นี้
+const AdminKeyHeader = "X-Admin-Key"
+
+// AdminName คืนชื่อ admin ของ key (ADMIN_API_KEYS="alice:key1,bob:key2" ชื่อใช้ใน audit)
+// key สั้นกว่า 16 ตัวไม่นับ กันตั้งค่าอ่อนเกินไปโดยไม่ตั้งใจ
+func AdminName(key string) (string, bool) {
+	if len(key) < 16 {
+		return "", false
+	}
+	name := ""
+	for _, entry := range strings.Split(os.Getenv("ADMIN_API_KEYS"), ",") {
+		n, k, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || len(k) < 16 {
+			continue
+		}
+		// เทียบครบทุก key ไม่หยุดกลางทาง
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 && name == "" {
+			name = n
+		}
+	}
+	return name, name != ""
+}
+
+type adminKey struct{}
+
+func WithAdmin(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, adminKey{}, name)
+}
+
+// AdminFromContext คืนชื่อ admin ที่ middleware.RequireAdmin ยืนยันแล้ว
+func AdminFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(adminKey{}).(string)
+	return name, ok && name != ""
+}
@@ -0,0 +1,25 @@
+// internal/auth/context.go
+package auth
+
+import "context"
+
+// Identity คือผู้เล่นที่ middleware ยืนยันตัวตนแล้วของ request นี้
+type Identity struct {
+	PlayerID  string
+	SessionID string // "" = มาจาก device token (guest)
+}
+
+// Registered: มาจาก access token ของบัญชีที่ login
+func (id Identity) Registered() bool { return id.SessionID != "" }
+
+type ctxKey struct{}
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext คืน identity ถ้า request นี้ส่ง token มา
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(ctxKey{}).(Identity)
+	return id, ok && id.PlayerID != ""
+}
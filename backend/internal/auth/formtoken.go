@@ -0,0 +1,59 @@
+// internal/auth/formtoken.go
+package auth
+
+import (
+	"crypto/hmac"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFormTooFast = errors.New("form submitted too fast")
+	ErrFormReused  = errors.New("form token already used")
+)
+
+// FormTokenMaxAge อายุ form token (FORM_TOKEN_TTL เช่น "2h")
+func FormTokenMaxAge() time.Duration { return envDuration("FORM_TOKEN_TTL", 2*time.Hour) }
+
+// FormTokenMinAge เวลาขั้นต่ำระหว่างขอฟอร์มกับส่ง (FORM_TOKEN_MIN_AGE) — คนพิมพ์ไม่เสร็จใน 2 วินาที
+func FormTokenMinAge() time.Duration { return envDuration("FORM_TOKEN_MIN_AGE", 2*time.Second) }
+
+// IssueFormToken: "f1.<form>.<nonce>.<issued>.<sig>" ผูกกับชื่อฟอร์ม ใช้ได้ครั้งเดียว
+func IssueFormToken(form string, now time.Time) (string, error) {
+	nonce, err := NewID()
+	if err != nil {
+		return "", err
+	}
+	payload := form + "." + nonce + "." + strconv.FormatInt(now.Unix(), 10)
+	return "f1." + payload + "." + mac("form", payload), nil
+}
+
+// VerifyFormToken ตรวจลายเซ็น อายุ และการใช้ซ้ำ
+func VerifyFormToken(tok, form string, now time.Time) error {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 5 || parts[0] != "f1" || parts[1] != form || parts[2] == "" {
+		return ErrInvalidToken
+	}
+	payload := parts[1] + "." + parts[2] + "." + parts[3]
+	if !hmac.Equal([]byte(parts[4]), []byte(mac("form", payload))) {
+		return ErrInvalidToken
+	}
+	iat, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	issued := time.Unix(iat, 0)
+	age := now.Sub(issued)
+	if age < FormTokenMinAge() {
+		return ErrFormTooFast
+	}
+	if age > FormTokenMaxAge() {
+		return ErrExpiredToken
+	}
+	if !useNonce("form:"+parts[2], issued.Add(FormTokenMaxAge())) {
+		return ErrFormReused
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// internal/auth/password.go
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var ErrBadHash = errors.New("malformed password hash")
+
+// argon2id (ค่าแนะนำของ OWASP ขั้นต่ำ: m=19MiB t=2 p=1 — เราใช้สูงกว่าเล็กน้อย)
+const (
+	argonTime    = 2
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 1
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// HashPassword คืน hash รูปแบบ PHC: $argon2id$v=19$m=65536,t=2,p=1$<salt>$<hash>
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// CheckPassword เทียบรหัสกับ hash (ใช้พารามิเตอร์ที่เก็บไว้ใน hash)
+func CheckPassword(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrBadHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrBadHash
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, ErrBadHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrBadHash
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, ErrBadHash
+	}
+	got := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// dummyHash ใช้ตอน username ไม่มีอยู่จริง ให้เวลาตอบพอ ๆ กับกรณีรหัสผิด
+var dummyHash, _ = HashPassword("dummy-password-for-timing")
+
+// CheckDummyPassword เสียเวลาเท่ากับ CheckPassword หนึ่งครั้ง (ผลเป็น false เสมอ)
+func CheckDummyPassword(password string) {
+	_, _ = CheckPassword(dummyHash, password)
+}
@@ -0,0 +1,71 @@
+// internal/auth/receipt.go
+package auth
+
+import (
+	"crypto/hmac"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScoreReceiptTTL อายุ receipt (SCORE_RECEIPT_TTL เช่น "10m")
+func ScoreReceiptTTL() time.Duration { return envDuration("SCORE_RECEIPT_TTL", 10*time.Minute) }
+
+// IssueScoreReceipt: server รับรองว่า name ได้ score ในเกม game จริง
+// "s1.<nonce>.<exp>.<sig>" — game/name/score ไม่อยู่ใน token แต่อยู่ในลายเซ็น
+func IssueScoreReceipt(game, name string, score int, now time.Time) (string, error) {
+	nonce, err := NewID()
+	if err != nil {
+		return "", err
+	}
+	exp := strconv.FormatInt(now.Add(ScoreReceiptTTL()).Unix(), 10)
+	return "s1." + nonce + "." + exp + "." + mac("score", receiptPayload(nonce, exp, game, name, score)), nil
+}
+
+func receiptPayload(nonce, exp, game, name string, score int) string {
+	return nonce + "|" + exp + "|" + game + "|" + name + "|" + strconv.Itoa(score)
+}
+
+// VerifyScoreReceipt ตรวจลายเซ็นและอายุของ receipt กับคะแนนที่ส่งมา คืน nonce และเวลาหมดอายุ
+// ไม่ได้จดว่าใช้แล้ว — ผู้เรียกต้องจด nonce ไว้ที่ฐานข้อมูลพร้อมกับบันทึกคะแนน (db.InsertScore)
+// บันทึกไม่สำเร็จ receipt จะได้ไม่เสียไปเปล่า ๆ และใช้ซ้ำข้าม restart/instance ไม่ได้
+func VerifyScoreReceipt(tok, game, name string, score int, now time.Time) (nonce string, expires time.Time, err error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 4 || parts[0] != "s1" || parts[1] == "" {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[3]), []byte(mac("score", receiptPayload(parts[1], parts[2], game, name, score)))) {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	if now.Unix() >= exp {
+		return "", time.Time{}, ErrExpiredToken
+	}
+	return parts[1], time.Unix(exp, 0), nil
+}
+
+// nonce ของ form token ที่ใช้ไปแล้ว จำไว้ในหน่วยความจำจนกว่า token จะหมดอายุ กันส่งซ้ำ
+var (
+	usedMu     sync.Mutex
+	usedNonces = map[string]time.Time{}
+)
+
+func useNonce(nonce string, until time.Time) bool {
+	usedMu.Lock()
+	defer usedMu.Unlock()
+	now := time.Now()
+	for n, u := range usedNonces {
+		if now.After(u) {
+			delete(usedNonces, n)
+		}
+	}
+	if _, ok := usedNonces[nonce]; ok {
+		return false
+	}
+	usedNonces[nonce] = until
+	return true
+}
@@ -0,0 +1,35 @@
+// internal/auth/revoked.go
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// access token ตรวจด้วยลายเซ็นอย่างเดียว ตอน logout จึงจำ session ที่ถูกยกเลิกไว้ในหน่วยความจำ
+// จนกว่า access token ที่ออกไปแล้วจะหมดอายุเอง (ไม่เกิน AccessTTL) — ทางลัดของ instance นี้
+// ตัวจริงคือ sessions.revoked_at ที่ middleware.Authenticate ตรวจซ้ำ (cache สั้น ๆ)
+// จึงมีผลข้าม restart/instance ด้วย
+var (
+	revokedMu sync.Mutex
+	revoked   = map[string]time.Time{} // sessionID -> ลบทิ้งได้หลังเวลานี้
+)
+
+func RevokeSession(sessionID string) {
+	revokedMu.Lock()
+	defer revokedMu.Unlock()
+	now := time.Now()
+	for id, until := range revoked {
+		if now.After(until) {
+			delete(revoked, id)
+		}
+	}
+	revoked[sessionID] = now.Add(AccessTTL())
+}
+
+func IsSessionRevoked(sessionID string) bool {
+	revokedMu.Lock()
+	defer revokedMu.Unlock()
+	until, ok := revoked[sessionID]
+	return ok && time.Now().Before(until)
+}
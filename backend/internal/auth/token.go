@@ -0,0 +1,131 @@
+// internal/auth/token.go
+package auth
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// ใช้ PLAYER_TOKEN_SECRET ถ้ามี ไม่งั้นใช้ HMAC_SECRET ตัวเดียวกับ quiz token
+func secret() []byte {
+	sec := os.Getenv("PLAYER_TOKEN_SECRET")
+	if sec == "" {
+		sec = os.Getenv("HMAC_SECRET")
+	}
+	if sec == "" {
+		sec = "change-me-in-env"
+	}
+	return []byte(sec)
+}
+
+// NewID สุ่ม id 128-bit แบบ hex
+func NewID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func mac(purpose, payload string) string {
+	m := hmac.New(sha256.New, secret())
+	m.Write([]byte(purpose + "|" + payload))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
+
+// DeviceTokenHeader: guest ส่ง device token มาทาง header นี้
+const DeviceTokenHeader = "X-Player-Token"
+
+// IssueDeviceToken: "d1.<playerID>.<sig>" ไม่มีวันหมดอายุ เก็บไว้ในเครื่องของ guest
+func IssueDeviceToken(playerID string) string {
+	return "d1." + playerID + "." + mac("device", playerID)
+}
+
+// VerifyDeviceToken คืน playerID ถ้าลายเซ็นถูกต้อง
+func VerifyDeviceToken(tok string) (string, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 || parts[0] != "d1" || parts[1] == "" {
+		return "", ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(mac("device", parts[1]))) {
+		return "", ErrInvalidToken
+	}
+	return parts[1], nil
+}
+
+// AccessTTL อายุ access token (ACCESS_TOKEN_TTL เช่น "15m")
+func AccessTTL() time.Duration { return envDuration("ACCESS_TOKEN_TTL", 15*time.Minute) }
+
+// RefreshTTL อายุ refresh token / session (REFRESH_TOKEN_TTL เช่น "720h")
+func RefreshTTL() time.Duration { return envDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour) }
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// IssueAccessToken: "a1.<playerID>.<sessionID>.<exp>.<sig>" อายุสั้น ตรวจได้โดยไม่ต้องแตะ DB
+func IssueAccessToken(playerID, sessionID string, now time.Time) (string, time.Time) {
+	exp := now.Add(AccessTTL())
+	payload := playerID + "." + sessionID + "." + strconv.FormatInt(exp.Unix(), 10)
+	return "a1." + payload + "." + mac("access", payload), exp
+}
+
+// VerifyAccessToken คืน playerID, sessionID ของ token ที่ยังไม่หมดอายุ
+func VerifyAccessToken(tok string, now time.Time) (playerID, sessionID string, err error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 5 || parts[0] != "a1" || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrInvalidToken
+	}
+	payload := parts[1] + "." + parts[2] + "." + parts[3]
+	if !hmac.Equal([]byte(parts[4]), []byte(mac("access", payload))) {
+		return "", "", ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", "", ErrInvalidToken
+	}
+	if now.Unix() >= exp {
+		return "", "", ErrExpiredToken
+	}
+	return parts[1], parts[2], nil
+}
+
+// NewRefreshToken: "r1.<sessionID>.<secret>" — DB เก็บแค่ HashRefreshSecret(secret)
+func NewRefreshToken(sessionID string) (token, secretHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := crand.Read(buf); err != nil {
+		return "", "", err
+	}
+	sec := base64.RawURLEncoding.EncodeToString(buf)
+	return "r1." + sessionID + "." + sec, HashRefreshSecret(sec), nil
+}
+
+// ParseRefreshToken แยก sessionID กับ secret ออกจาก refresh token
+func ParseRefreshToken(tok string) (sessionID, sec string, err error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 || parts[0] != "r1" || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrInvalidToken
+	}
+	return parts[1], parts[2], nil
+}
+
+func HashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Round modes share the same duration policy but can be tuned separately.
+const (
+	ModeClassic = "classic" // GET /api/quiz
+	ModeChoice  = "choice"  // GET /api/quiz/multiple-choice
+	ModeParty   = "party"   // party rooms
+)
+
+const defaultRoundSeconds = 60
+
+// RoundSecondsBounds returns the range any round duration is clamped to.
+//
+//	ROUND_SECONDS_MIN (default 10)
+//	ROUND_SECONDS_MAX (default 300)
+func RoundSecondsBounds() (min, max int) {
+	min = getInt("ROUND_SECONDS_MIN", 10)
+	max = getInt("ROUND_SECONDS_MAX", 300)
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// ClampRoundSeconds keeps n inside RoundSecondsBounds.
+func ClampRoundSeconds(n int) int {
+	min, max := RoundSecondsBounds()
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// RoundSeconds returns the default round duration for a mode and tier.
+// Lookup order (first non-empty wins):
+//
+//	ROUND_SECONDS_<MODE>_TIER<n>   e.g. ROUND_SECONDS_PARTY_TIER1=45
+//	ROUND_SECONDS_TIER<n>          e.g. ROUND_SECONDS_TIER3=90
+//	ROUND_SECONDS                  global default
+//	60
+func RoundSeconds(mode string, tier int) int {
+	t := strconv.Itoa(tier)
+	n := defaultRoundSeconds
+	for _, key := range []string{
+		"ROUND_SECONDS_" + strings.ToUpper(mode) + "_TIER" + t,
+		"ROUND_SECONDS_TIER" + t,
+		"ROUND_SECONDS",
+	} {
+		if v, ok := lookupInt(key); ok {
+			n = v
+			break
+		}
+	}
+	return ClampRoundSeconds(n)
+}
+
+func lookupInt(key string) (int, bool) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func getInt(key string, def int) int {
+	if n, ok := lookupInt(key); ok {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,165 @@
+// internal/db/accounts.go
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrUsernameTaken = errors.New("username taken")
+	ErrNoAccount     = errors.New("no account")
+	ErrNoSession     = errors.New("no session")
+)
+
+type Account struct {
+	PlayerID     string
+	Username     string
+	PasswordHash string
+	Player       Player
+}
+
+// CreateAccount สร้าง player แบบ registered พร้อม credential ใน transaction เดียว
+func CreateAccount(ctx context.Context, playerID, username, displayName, passwordHash string) (Player, error) {
+	if pool == nil {
+		return Player{}, ErrNotInitialized
+	}
+	p := Player{ID: playerID, DisplayName: displayName, Kind: "registered"}
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO public.players(id, display_name, kind)
+			VALUES ($1, $2, 'registered')
+			RETURNING created_at
+		`, playerID, displayName).Scan(&p.CreatedAt); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO public.accounts(player_id, username, password_hash)
+			VALUES ($1, $2, $3)
+		`, playerID, username, passwordHash)
+		return err
+	})
+	if name, ok := uniqueViolation(err); ok {
+		if name == "uq_accounts_username" {
+			return Player{}, ErrUsernameTaken
+		}
+		return Player{}, ErrNameTaken
+	}
+	return p, err
+}
+
+func GetAccountByUsername(ctx context.Context, username string) (Account, error) {
+	if pool == nil {
+		return Account{}, ErrNotInitialized
+	}
+	var a Account
+	err := pool.QueryRow(ctx, `
+		SELECT a.player_id, a.username, a.password_hash,
+		       p.id, p.display_name, p.kind, p.created_at
+		FROM public.accounts a
+		JOIN public.players p ON p.id = a.player_id
+		WHERE lower(a.username) = lower($1)
+	`, username).Scan(&a.PlayerID, &a.Username, &a.PasswordHash,
+		&a.Player.ID, &a.Player.DisplayName, &a.Player.Kind, &a.Player.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return a, ErrNoAccount
+	}
+	return a, err
+}
+
+// ===== Sessions =====
+
+type Session struct {
+	ID          string
+	PlayerID    string
+	RefreshHash string
+	ExpiresAt   time.Time
+	RevokedAt   *time.Time
+}
+
+func CreateSession(ctx context.Context, s Session, userAgent string) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO public.sessions(id, player_id, refresh_hash, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, s.ID, s.PlayerID, s.RefreshHash, userAgent, s.ExpiresAt)
+	return err
+}
+
+func GetSession(ctx context.Context, id string) (Session, error) {
+	if pool == nil {
+		return Session{}, ErrNotInitialized
+	}
+	var s Session
+	err := pool.QueryRow(ctx, `
+		SELECT id, player_id, refresh_hash, expires_at, revoked_at
+		FROM public.sessions
+		WHERE id = $1
+	`, id).Scan(&s.ID, &s.PlayerID, &s.RefreshHash, &s.ExpiresAt, &s.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, ErrNoSession
+	}
+	return s, err
+}
+
+// RotateSession เปลี่ยน refresh hash จาก oldHash เป็น newHash
+// ถ้ามีใครหมุนไปก่อนแล้ว (hash ไม่ตรง) / หมดอายุ / ถูก revoke คืน ErrNoSession
+func RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `
+		UPDATE public.sessions
+		SET refresh_hash = $3, expires_at = $4, last_used_at = now()
+		WHERE id = $1 AND refresh_hash = $2
+		  AND revoked_at IS NULL AND expires_at > now()
+	`, id, oldHash, newHash, expiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoSession
+	}
+	return nil
+}
+
+func RevokeSession(ctx context.Context, id string) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		UPDATE public.sessions SET revoked_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	return err
+}
+
+// RevokePlayerSessions ยกเลิกทุก session ของ player คืน id ที่ถูกยกเลิก
+func RevokePlayerSessions(ctx context.Context, playerID string) ([]string, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		UPDATE public.sessions SET revoked_at = now()
+		WHERE player_id = $1 AND revoked_at IS NULL
+		RETURNING id
+	`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
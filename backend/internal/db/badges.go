@@ -0,0 +1,100 @@
+// internal/db/badges.go
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type PlayerBadge struct {
+	BadgeID    string
+	UnlockedAt time.Time
+}
+
+// UnlockBadge: true = เพิ่งปลดล็อกครั้งนี้ (มีอยู่แล้วคืน false)
+func UnlockBadge(ctx context.Context, playerID, badgeID string) (bool, error) {
+	if pool == nil {
+		return false, ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `
+		INSERT INTO public.player_badges(player_id, badge_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, playerID, badgeID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func HasBadge(ctx context.Context, playerID, badgeID string) (bool, error) {
+	if pool == nil {
+		return false, ErrNotInitialized
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM public.player_badges WHERE player_id = $1 AND badge_id = $2)
+	`, playerID, badgeID).Scan(&ok)
+	return ok, err
+}
+
+// ListPlayerBadges เรียงตามเวลาที่ปลดล็อก (เก่าก่อน)
+func ListPlayerBadges(ctx context.Context, playerID string) ([]PlayerBadge, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT badge_id, unlocked_at FROM public.player_badges
+		WHERE player_id = $1
+		ORDER BY unlocked_at, badge_id
+	`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []PlayerBadge
+	for rows.Next() {
+		var b PlayerBadge
+		if err := rows.Scan(&b.BadgeID, &b.UnlockedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// CountSolvedWithoutHints นับคำที่ตอบถูกโดยไม่ขอ hint เลย (รวมไม่ถามผู้ช่วย AI)
+func CountSolvedWithoutHints(ctx context.Context, playerID string) (int, error) {
+	if pool == nil {
+		return 0, ErrNotInitialized
+	}
+	var n int
+	err := pool.QueryRow(ctx, `
+		SELECT count(*) FROM public.quiz_runs
+		WHERE player_id = $1 AND solved AND hints_used = 0 AND ai_questions = 0
+	`, playerID).Scan(&n)
+	return n, err
+}
+
+// SolveStreaks นับวัน (ตามปฏิทินของ tz) ที่ตอบถูกอย่างน้อยหนึ่งคำติดต่อกัน
+// current = streak ที่จบวันนี้หรือเมื่อวาน (ยังไม่ขาด), longest = ยาวที่สุดที่เคยทำได้
+func SolveStreaks(ctx context.Context, playerID, tz string) (current, longest int, err error) {
+	if pool == nil {
+		return 0, 0, ErrNotInitialized
+	}
+	err = pool.QueryRow(ctx, `
+		WITH days AS (
+			SELECT DISTINCT (solved_at AT TIME ZONE $2)::date AS d
+			FROM public.quiz_runs
+			WHERE player_id = $1 AND solved
+		), runs AS (
+			SELECT d, d - (ROW_NUMBER() OVER (ORDER BY d))::int AS grp FROM days
+		), streaks AS (
+			SELECT max(d) AS last_day, count(*) AS len FROM runs GROUP BY grp
+		)
+		SELECT COALESCE(max(len) FILTER (WHERE last_day >= (now() AT TIME ZONE $2)::date - 1), 0),
+		       COALESCE(max(len), 0)
+		FROM streaks
+	`, playerID, tz).Scan(&current, &longest)
+	return current, longest, err
+}
@@ -0,0 +1,218 @@
+// internal/db/chat.go
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNoConversation = errors.New("no conversation")
+
+type ChatConversation struct {
+	ID        int64
+	PlayerID  string
+	UseCase   string
+	Title     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ChatMessage struct {
+	ID             int64
+	ConversationID int64
+	Role           string // "user" | "assistant"
+	Content        string
+	Tokens         int
+	CreatedAt      time.Time
+}
+
+const chatConversationCols = `id, player_id, use_case, title, created_at, updated_at`
+
+func scanChatConversation(row pgx.Row) (ChatConversation, error) {
+	var c ChatConversation
+	err := row.Scan(&c.ID, &c.PlayerID, &c.UseCase, &c.Title, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, ErrNoConversation
+	}
+	return c, err
+}
+
+// GetChatConversation: ต้องเป็นของ playerID (ของคนอื่น = ErrNoConversation ไม่บอกว่ามีอยู่)
+func GetChatConversation(ctx context.Context, playerID string, id int64) (ChatConversation, error) {
+	if pool == nil {
+		return ChatConversation{}, ErrNotInitialized
+	}
+	return scanChatConversation(pool.QueryRow(ctx, `
+		SELECT `+chatConversationCols+` FROM public.chat_conversations WHERE id = $1 AND player_id = $2
+	`, id, playerID))
+}
+
+// ListChatConversations: ใหม่สุดก่อน
+func ListChatConversations(ctx context.Context, playerID string, cur *Cursor, limit int) ([]ChatConversation, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	cond, order, cargs := createdKeyset(cur, 2)
+	rows, err := pool.Query(ctx, `
+		SELECT `+chatConversationCols+`
+		FROM public.chat_conversations
+		WHERE player_id = $1 AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $2
+	`, append([]any{playerID, limit}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ChatConversation
+	for rows.Next() {
+		c, err := scanChatConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
+
+// DeleteChatConversation ลบบทสนทนาพร้อมข้อความทั้งหมด
+func DeleteChatConversation(ctx context.Context, playerID string, id int64) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM public.chat_conversations WHERE id = $1 AND player_id = $2`, id, playerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoConversation
+	}
+	return nil
+}
+
+const chatMessageCols = `id, conversation_id, role, content, tokens, created_at`
+
+func scanChatMessage(row pgx.Row) (ChatMessage, error) {
+	var m ChatMessage
+	err := row.Scan(&m.ID, &m.ConversationID, &m.Role, &m.Content, &m.Tokens, &m.CreatedAt)
+	return m, err
+}
+
+func collectChatMessages(rows pgx.Rows) ([]ChatMessage, error) {
+	defer rows.Close()
+	var out []ChatMessage
+	for rows.Next() {
+		m, err := scanChatMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// ListChatMessages: ใหม่สุดก่อน (ใช้แบ่งหน้าย้อนดูประวัติ)
+func ListChatMessages(ctx context.Context, conversationID int64, cur *Cursor, limit int) ([]ChatMessage, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	cond, order, cargs := createdKeyset(cur, 2)
+	rows, err := pool.Query(ctx, `
+		SELECT `+chatMessageCols+`
+		FROM public.chat_messages
+		WHERE conversation_id = $1 AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $2
+	`, append([]any{conversationID, limit}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := collectChatMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
+
+// RecentChatMessages คืน n ข้อความล่าสุด เรียงเก่า → ใหม่ (ส่งต่อให้ provider)
+func RecentChatMessages(ctx context.Context, conversationID int64, n int) ([]ChatMessage, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT `+chatMessageCols+`
+		FROM public.chat_messages
+		WHERE conversation_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, conversationID, n)
+	if err != nil {
+		return nil, err
+	}
+	out, err := collectChatMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	reverse(out)
+	return out, nil
+}
+
+// ChatTurn คือคำถามกับคำตอบหนึ่งรอบ ConversationID = 0 → สร้างบทสนทนาใหม่ (UseCase/Title ใช้ตอนสร้าง)
+type ChatTurn struct {
+	ConversationID int64
+	PlayerID       string
+	UseCase        string
+	Title          string
+	Messages       []ChatMessage
+}
+
+// SaveChatTurn บันทึกทั้งรอบใน transaction เดียว คืนบทสนทนา (updated_at ใหม่)
+func SaveChatTurn(ctx context.Context, t ChatTurn) (ChatConversation, error) {
+	if pool == nil {
+		return ChatConversation{}, ErrNotInitialized
+	}
+	var c ChatConversation
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		var err error
+		if t.ConversationID == 0 {
+			c, err = scanChatConversation(tx.QueryRow(ctx, `
+				INSERT INTO public.chat_conversations(player_id, use_case, title)
+				VALUES ($1, $2, $3)
+				RETURNING `+chatConversationCols,
+				t.PlayerID, t.UseCase, t.Title))
+		} else {
+			// ถูกลบไประหว่างรอคำตอบ = ErrNoConversation
+			c, err = scanChatConversation(tx.QueryRow(ctx, `
+				UPDATE public.chat_conversations SET updated_at = now()
+				WHERE id = $1 AND player_id = $2
+				RETURNING `+chatConversationCols,
+				t.ConversationID, t.PlayerID))
+		}
+		if err != nil {
+			return err
+		}
+		for _, m := range t.Messages {
+			// clock_timestamp ให้เวลาต่างกันตามลำดับ (now() เท่ากันทั้ง transaction)
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO public.chat_messages(conversation_id, role, content, tokens, created_at)
+				VALUES ($1, $2, $3, $4, clock_timestamp())
+			`, c.ID, m.Role, m.Content, m.Tokens); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return c, err
+}
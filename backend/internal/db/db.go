@@ -13,6 +13,8 @@ import (
 
 var pool *pgxpool.Pool
 var ErrNoQuiz = errors.New("no quiz")
+var ErrNoScore = errors.New("no score")
+var ErrReceiptUsed = errors.New("score receipt already used")
 
 func Init(ctx context.Context) error {
 	dsn := os.Getenv("DATABASE_URL")
@@ -47,37 +49,141 @@ func Close() {
 }
 
 type ScoreRow struct {
+	ID        int64
 	Name      string
 	Score     int
 	GameName  string
 	CreatedAt time.Time
 }
 
-func InsertScore(ctx context.Context, name string, score int, gamename string) error {
-	_, err := pool.Exec(ctx,
-		`INSERT INTO public.scores(name, score, gamename) VALUES ($1, $2, $3)`,
-		name, score, gamename,
-	)
-	return err
+// ScoreReceipt คือ receipt ที่ตรวจลายเซ็นแล้ว ใช้คู่กับ InsertScore ได้ครั้งเดียว
+type ScoreReceipt struct {
+	Nonce     string
+	ExpiresAt time.Time
 }
 
-func GetTopScores(ctx context.Context, gamename string, limit int) ([]ScoreRow, error) {
+// InsertScore: playerID ว่าง = ไม่ระบุตัวตน (player_id เป็น NULL)
+// receipt ไม่ใช่ nil = จด nonce ว่าใช้แล้วใน transaction เดียวกับคะแนน
+// (บันทึกไม่สำเร็จ receipt ยังใช้ได้ ใช้ซ้ำ = ErrReceiptUsed)
+func InsertScore(ctx context.Context, name string, score int, gamename, playerID string, receipt *ScoreReceipt) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if receipt != nil {
+			if _, err := tx.Exec(ctx, `DELETE FROM public.used_score_receipts WHERE expires_at < now()`); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO public.used_score_receipts(nonce, expires_at) VALUES ($1, $2)
+			`, receipt.Nonce, receipt.ExpiresAt); err != nil {
+				if isUniqueViolation(err) {
+					return ErrReceiptUsed
+				}
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO public.scores(name, score, gamename, player_id, season_id)
+			 VALUES ($1, $2, $3, NULLIF($4, ''), (
+			   SELECT id FROM public.seasons WHERE starts_at <= now() AND now() < ends_at
+			   ORDER BY starts_at DESC LIMIT 1
+			 ))`,
+			name, score, gamename, playerID,
+		)
+		return err
+	})
+}
+
+// TimeRange จำกัดช่วง created_at และ season ของ leaderboard (ค่า zero = ไม่จำกัด)
+type TimeRange struct {
+	From   time.Time // inclusive
+	To     time.Time // exclusive
+	Season int64     // 0 = ทุก season
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// GetTopScores คืน leaderboard ต่อจาก cur (nil = หน้าแรก) ตามลำดับที่แสดงผล
+// scoreOrder = Game.ScoreOrder ("" = มากดีกว่า)
+func GetTopScores(ctx context.Context, gamename, scoreOrder string, rng TimeRange, cur *Cursor, limit int) ([]ScoreRow, error) {
+	cond, order, cargs := scoreKeyset(cur, 5, scoreOrder)
 	rows, err := pool.Query(ctx, `
-		SELECT name, score, gamename, created_at
+		SELECT id, name, score, gamename, created_at
 		FROM public.scores
 		WHERE ($1 = '' OR gamename = $1)
-		ORDER BY score DESC, created_at ASC
-		LIMIT $2
-	`, gamename, limit)
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR created_at <  $3)
+		  AND ($5::bigint = 0 OR season_id = $5)
+		  AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $4
+	`, append([]any{gamename, nullTime(rng.From), nullTime(rng.To), limit, rng.Season}, cargs...)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanScorePage(rows, cur)
+}
 
+// GetBestScores เหมือน GetTopScores แต่เหลือแถวเดียวต่อผู้เล่นต่อเกม
+// (คะแนนที่ดีที่สุด ถ้าเท่ากันใช้อันที่ทำได้ก่อน)
+func GetBestScores(ctx context.Context, gamename, scoreOrder string, rng TimeRange, cur *Cursor, limit int) ([]ScoreRow, error) {
+	cond, order, cargs := scoreKeyset(cur, 5, scoreOrder)
+	rows, err := pool.Query(ctx, `
+		SELECT id, name, score, gamename, created_at
+		FROM (
+			SELECT DISTINCT ON (gamename, name) id, name, score, gamename, created_at
+			FROM public.scores
+			WHERE ($1 = '' OR gamename = $1)
+			  AND ($2::timestamptz IS NULL OR created_at >= $2)
+			  AND ($3::timestamptz IS NULL OR created_at <  $3)
+			  AND ($5::bigint = 0 OR season_id = $5)
+			ORDER BY gamename, name, `+scoreKey(scoreOrder)+` DESC, created_at ASC, id ASC
+		) best
+		WHERE `+cond+`
+		ORDER BY `+order+`
+		LIMIT $4
+	`, append([]any{gamename, nullTime(rng.From), nullTime(rng.To), limit, rng.Season}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScorePage(rows, cur)
+}
+
+// GetPlayerScores คืนประวัติคะแนนดิบของผู้เล่น (ใหม่สุดก่อน)
+//   - playerID ไม่ว่าง = คะแนนของ player นั้น รวมคะแนนเก่าที่ไม่มี player_id แต่ใช้ชื่อ name
+//   - playerID ว่าง = เฉพาะคะแนนที่ไม่มี player ที่ใช้ชื่อ name (คนละ player ชื่อซ้ำกันไม่ปนกัน)
+func GetPlayerScores(ctx context.Context, gamename, playerID, name string, limit int) ([]ScoreRow, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT id, name, score, gamename, created_at
+		FROM public.scores
+		WHERE (($4 <> '' AND player_id = $4) OR (player_id IS NULL AND name = $1))
+		  AND ($2 = '' OR gamename = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, name, gamename, limit, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScoreRows(rows)
+}
+
+func scanScoreRows(rows pgx.Rows) ([]ScoreRow, error) {
 	var out []ScoreRow
 	for rows.Next() {
 		var s ScoreRow
-		if err := rows.Scan(&s.Name, &s.Score, &s.GameName, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.Score, &s.GameName, &s.CreatedAt); err != nil {
 			return nil, err
 		}
 		out = append(out, s)
@@ -85,9 +191,83 @@ func GetTopScores(ctx context.Context, gamename string, limit int) ([]ScoreRow,
 	return out, rows.Err()
 }
 
+func scanScorePage(rows pgx.Rows, cur *Cursor) ([]ScoreRow, error) {
+	out, err := scanScoreRows(rows)
+	if err == nil && cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, err
+}
+
+// RankedScore คือคะแนนที่ดีที่สุดของผู้เล่นหนึ่งคนพร้อมอันดับ
+type RankedScore struct {
+	Rank       int // RANK(): คะแนนเท่ากันได้อันดับเดียวกัน
+	Position   int // ลำดับจริงในตาราง (ไม่ซ้ำ)
+	Name       string
+	Score      int
+	CreatedAt  time.Time
+	Total      int     // จำนวนผู้เล่นทั้งหมดในช่วงนี้
+	Percentile float64 // % ผู้เล่นที่คะแนนแย่กว่า
+}
+
+// GetScoreRank คืนคะแนนที่ดีที่สุดของ name พร้อมเพื่อนบ้าน k อันดับบน/ล่าง
+// (เรียงตาม Position) ถ้าผู้เล่นไม่มีคะแนนในช่วงนี้คืน ErrNoScore
+func GetScoreRank(ctx context.Context, gamename, scoreOrder, name string, rng TimeRange, k int) ([]RankedScore, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	key := scoreKey(scoreOrder)
+	rows, err := pool.Query(ctx, `
+		WITH best AS (
+			SELECT DISTINCT ON (name) name, score, created_at
+			FROM public.scores
+			WHERE gamename = $1
+			  AND ($3::timestamptz IS NULL OR created_at >= $3)
+			  AND ($4::timestamptz IS NULL OR created_at <  $4)
+			  AND ($6::bigint = 0 OR season_id = $6)
+			ORDER BY name, `+key+` DESC, created_at ASC
+		), ranked AS (
+			SELECT name, score, created_at,
+			       RANK()         OVER (ORDER BY `+key+` DESC)                 AS rnk,
+			       ROW_NUMBER()   OVER (ORDER BY `+key+` DESC, created_at ASC) AS pos,
+			       COUNT(*)       OVER ()                                      AS total,
+			       PERCENT_RANK() OVER (ORDER BY `+key+` ASC)                  AS pct
+			FROM best
+		), me AS (
+			SELECT pos FROM ranked WHERE name = $2
+		)
+		SELECT r.rnk, r.pos, r.name, r.score, r.created_at, r.total, r.pct
+		FROM ranked r, me
+		WHERE r.pos BETWEEN me.pos - $5 AND me.pos + $5
+		ORDER BY r.pos
+	`, gamename, name, nullTime(rng.From), nullTime(rng.To), k, rng.Season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RankedScore
+	for rows.Next() {
+		var s RankedScore
+		if err := rows.Scan(&s.Rank, &s.Position, &s.Name, &s.Score, &s.CreatedAt, &s.Total, &s.Percentile); err != nil {
+			return nil, err
+		}
+		s.Percentile *= 100
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNoScore
+	}
+	return out, nil
+}
+
 /* ===== Quizzes (คงเดิม) ===== */
 
 type QuizRow struct {
+	ID     int64
 	Answer string
 	Hint1  string
 	Hint2  string
@@ -115,12 +295,12 @@ func GetAllAnswers(ctx context.Context) ([]string, error) {
 func GetRandomQuizByTier(ctx context.Context, tier int) (QuizRow, error) {
 	var q QuizRow
 	err := pool.QueryRow(ctx, `
-		SELECT answer, hint1, hint2, tier
+		SELECT id, answer, hint1, hint2, tier
 		FROM public.quizzes
 		WHERE active AND tier = $1
 		ORDER BY random()
 		LIMIT 1
-	`, tier).Scan(&q.Answer, &q.Hint1, &q.Hint2, &q.Tier)
+	`, tier).Scan(&q.ID, &q.Answer, &q.Hint1, &q.Hint2, &q.Tier)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return q, ErrNoQuiz
 	}
@@ -130,12 +310,12 @@ func GetRandomQuizByTier(ctx context.Context, tier int) (QuizRow, error) {
 func GetRandomQuizByTierAndCategory(ctx context.Context, tier int, category string) (QuizRow, error) {
 	var q QuizRow
 	err := pool.QueryRow(ctx, `
-		SELECT answer, hint1, hint2, tier
+		SELECT id, answer, hint1, hint2, tier
 		FROM public.quizzes
 		WHERE active AND tier = $1 AND category = $2
 		ORDER BY random()
 		LIMIT 1
-	`, tier, category).Scan(&q.Answer, &q.Hint1, &q.Hint2, &q.Tier)
+	`, tier, category).Scan(&q.ID, &q.Answer, &q.Hint1, &q.Hint2, &q.Tier)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return q, ErrNoQuiz
 	}
@@ -143,14 +323,15 @@ func GetRandomQuizByTierAndCategory(ctx context.Context, tier int, category stri
 }
 
 type QuizLite struct {
-	Answer string
-	Hint1  string
-	Hint2  string
+	Answer  string
+	Hint1   string
+	Hint2   string
+	Aliases []string
 }
 
 func GetAllQuizLite(ctx context.Context) ([]QuizLite, error) {
 	rows, err := pool.Query(ctx, `
-        SELECT answer, hint1, hint2
+        SELECT answer, hint1, hint2, aliases
         FROM public.quizzes
         WHERE active = TRUE
     `)
@@ -162,7 +343,7 @@ func GetAllQuizLite(ctx context.Context) ([]QuizLite, error) {
 	out := []QuizLite{}
 	for rows.Next() {
 		var q QuizLite
-		if err := rows.Scan(&q.Answer, &q.Hint1, &q.Hint2); err != nil {
+		if err := rows.Scan(&q.Answer, &q.Hint1, &q.Hint2, &q.Aliases); err != nil {
 			return nil, err
 		}
 		out = append(out, q)
@@ -190,7 +371,7 @@ func GetQuizzes(ctx context.Context, level int, category string) ([]QuizRow, err
 	// Query with category filter if provided
 	if category != "" {
 		rows, err = pool.Query(ctx, `
-			SELECT answer, hint1, hint2, tier
+			SELECT id, answer, hint1, hint2, tier
 			FROM public.quizzes
 			WHERE active = TRUE AND tier = $1 AND category = $2
 			ORDER BY random()
@@ -198,7 +379,7 @@ func GetQuizzes(ctx context.Context, level int, category string) ([]QuizRow, err
 		`, tier, category)
 	} else {
 		rows, err = pool.Query(ctx, `
-			SELECT answer, hint1, hint2, tier
+			SELECT id, answer, hint1, hint2, tier
 			FROM public.quizzes
 			WHERE active = TRUE AND tier = $1
 			ORDER BY random()
@@ -214,7 +395,7 @@ func GetQuizzes(ctx context.Context, level int, category string) ([]QuizRow, err
 	var quizzes []QuizRow
 	for rows.Next() {
 		var q QuizRow
-		if err := rows.Scan(&q.Answer, &q.Hint1, &q.Hint2, &q.Tier); err != nil {
+		if err := rows.Scan(&q.ID, &q.Answer, &q.Hint1, &q.Hint2, &q.Tier); err != nil {
 			return nil, err
 		}
 		quizzes = append(quizzes, q)
@@ -226,6 +407,7 @@ func GetQuizzes(ctx context.Context, level int, category string) ([]QuizRow, err
 // ===== Feedbacks =====
 
 type FeedbackRow struct {
+	ID        int64
 	Name      string
 	Contact   string
 	Message   string
@@ -233,27 +415,72 @@ type FeedbackRow struct {
 	CreatedAt time.Time
 }
 
-func InsertFeedback(ctx context.Context, name, contact, message, source string) error {
+// NewFeedback คือ feedback ที่ผ่านการตรวจแล้ว (PlayerID ว่าง = ไม่ระบุตัวตน)
+type NewFeedback struct {
+	Name        string
+	Contact     string
+	Message     string
+	Source      string
+	PlayerID    string
+	IP          string
+	MessageHash string
+	Context     FeedbackContext
+	Attachments []Attachment // ไฟล์อยู่ใน storage แล้ว (ID/FeedbackID ไม่ต้องใส่)
+}
+
+// FeedbackContext ข้อมูลประกอบที่เก็บคู่กับ feedback (คอลัมน์ context แบบ JSONB)
+// ฝั่ง server เติมจาก request, ส่วน Route/RoomCode/QuizID มาจาก client
+type FeedbackContext struct {
+	RequestID  string `json:"request_id,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	AppVersion string `json:"app_version,omitempty"`
+	Locale     string `json:"locale,omitempty"`
+	Route      string `json:"route,omitempty"`
+	RoomCode   string `json:"room_code,omitempty"`
+	QuizID     string `json:"quiz_id,omitempty"`
+}
+
+// InsertFeedback บันทึก feedback พร้อมรูปแนบใน transaction เดียว คืน id ใหม่
+func InsertFeedback(ctx context.Context, f NewFeedback) (int64, error) {
 	if pool == nil {
-		return fmt.Errorf("db pool is nil")
+		return 0, fmt.Errorf("db pool is nil")
 	}
-	_, err := pool.Exec(ctx, `
-		INSERT INTO public.feedbacks(name, contact, message, source)
-		VALUES ($1, $2, $3, $4)
-	`, name, contact, message, source)
-	return err
+	var id int64
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO public.feedbacks(name, contact, message, source, player_id, ip, message_hash, context)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+			RETURNING id
+		`, f.Name, f.Contact, f.Message, f.Source, f.PlayerID, f.IP, f.MessageHash, f.Context).Scan(&id)
+		if err != nil {
+			return err
+		}
+		for _, a := range f.Attachments {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO public.feedback_attachments(feedback_id, storage_key, thumb_key, content_type, size_bytes, width, height)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, id, a.StorageKey, a.ThumbKey, a.ContentType, a.Size, a.Width, a.Height); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return id, err
 }
 
-func GetRecentFeedbacks(ctx context.Context, limit int) ([]FeedbackRow, error) {
+// GetRecentFeedbacks คืน feedback ที่ admin อนุมัติแล้ว ใหม่สุดก่อน ต่อจาก cur (nil = หน้าแรก)
+func GetRecentFeedbacks(ctx context.Context, cur *Cursor, limit int) ([]FeedbackRow, error) {
 	if pool == nil {
 		return nil, fmt.Errorf("db pool is nil")
 	}
+	cond, order, cargs := createdKeyset(cur, 1)
 	rows, err := pool.Query(ctx, `
-		SELECT name, contact, message, source, created_at
+		SELECT id, name, contact, message, source, created_at
 		FROM public.feedbacks
-		ORDER BY created_at DESC
+		WHERE published AND `+cond+`
+		ORDER BY `+order+`
 		LIMIT $1
-	`, limit)
+	`, append([]any{limit}, cargs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -262,10 +489,16 @@ func GetRecentFeedbacks(ctx context.Context, limit int) ([]FeedbackRow, error) {
 	var out []FeedbackRow
 	for rows.Next() {
 		var f FeedbackRow
-		if err := rows.Scan(&f.Name, &f.Contact, &f.Message, &f.Source, &f.CreatedAt); err != nil {
+		if err := rows.Scan(&f.ID, &f.Name, &f.Contact, &f.Message, &f.Source, &f.CreatedAt); err != nil {
 			return nil, err
 		}
 		out = append(out, f)
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
 }
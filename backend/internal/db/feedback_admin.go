@@ -0,0 +1,223 @@
+// internal/db/feedback_admin.go
+package db
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNoFeedback    = errors.New("feedback not found")
+	ErrBadTransition = errors.New("status transition not allowed")
+	ErrUnknownStatus = errors.New("unknown status")
+)
+
+// สถานะที่เปลี่ยนไปได้จากแต่ละสถานะ
+var feedbackTransitions = map[string][]string{
+	"new":      {"triaged", "resolved", "spam"},
+	"triaged":  {"resolved", "spam"},
+	"resolved": {"triaged", "spam"}, // เปิดใหม่ หรือพบทีหลังว่าเป็น spam
+	"spam":     {"new"},             // ไม่ใช่ spam
+}
+
+// FeedbackStatuses ตามลำดับ workflow
+var FeedbackStatuses = []string{"new", "triaged", "resolved", "spam"}
+
+// CanTransition: เปลี่ยนสถานะจาก from เป็น to ได้ไหม (ค่าเดิมถือว่าได้)
+func CanTransition(from, to string) bool {
+	return from == to || slices.Contains(feedbackTransitions[from], to)
+}
+
+// AdminFeedback คือ feedback แบบเต็ม (มี contact) สำหรับ admin เท่านั้น
+type AdminFeedback struct {
+	FeedbackRow
+	PlayerID  string
+	Status    string
+	Tags      []string
+	Published bool
+	UpdatedAt time.Time
+	Context   FeedbackContext
+}
+
+const adminFeedbackCols = `id, name, contact, message, source, created_at,
+	COALESCE(player_id, ''), status, tags, published, updated_at, context`
+
+func scanAdminFeedback(row pgx.Row) (AdminFeedback, error) {
+	var f AdminFeedback
+	err := row.Scan(&f.ID, &f.Name, &f.Contact, &f.Message, &f.Source, &f.CreatedAt,
+		&f.PlayerID, &f.Status, &f.Tags, &f.Published, &f.UpdatedAt, &f.Context)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return f, ErrNoFeedback
+	}
+	return f, err
+}
+
+// FeedbackFilter ค่าว่าง = ไม่กรอง
+type FeedbackFilter struct {
+	Status string
+	Source string
+	Tag    string
+	Range  TimeRange
+
+	RequestID string // context.request_id (ตามรอยจาก log)
+	RoomCode  string // context.room_code (ตัวใหญ่)
+}
+
+// ListAdminFeedback ใหม่สุดก่อน ต่อจาก cur
+func ListAdminFeedback(ctx context.Context, f FeedbackFilter, cur *Cursor, limit int) ([]AdminFeedback, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	cond, order, cargs := createdKeyset(cur, 8)
+	rows, err := pool.Query(ctx, `
+		SELECT `+adminFeedbackCols+`
+		FROM public.feedbacks
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR source = $2)
+		  AND ($3 = '' OR $3 = ANY(tags))
+		  AND ($4::timestamptz IS NULL OR created_at >= $4)
+		  AND ($5::timestamptz IS NULL OR created_at <  $5)
+		  AND ($7 = '' OR context->>'request_id' = $7)
+		  AND ($8 = '' OR context->>'room_code' = $8)
+		  AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $6
+	`, append([]any{f.Status, f.Source, f.Tag, nullTime(f.Range.From), nullTime(f.Range.To), limit, f.RequestID, f.RoomCode}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []AdminFeedback
+	for rows.Next() {
+		fb, err := scanAdminFeedback(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
+
+func GetAdminFeedback(ctx context.Context, id int64) (AdminFeedback, error) {
+	if pool == nil {
+		return AdminFeedback{}, ErrNotInitialized
+	}
+	return scanAdminFeedback(pool.QueryRow(ctx, `SELECT `+adminFeedbackCols+` FROM public.feedbacks WHERE id = $1`, id))
+}
+
+// FeedbackPatch: nil = ไม่เปลี่ยน
+type FeedbackPatch struct {
+	Status    *string
+	Tags      *[]string
+	Published *bool
+}
+
+// UpdateFeedback แก้สถานะ/tag/การเผยแพร่ การเปลี่ยนสถานะบันทึกเป็น note (kind = status) ด้วย
+// ตั้งเป็น spam แล้วเลิกเผยแพร่อัตโนมัติ
+func UpdateFeedback(ctx context.Context, id int64, p FeedbackPatch, actor string) (AdminFeedback, error) {
+	if pool == nil {
+		return AdminFeedback{}, ErrNotInitialized
+	}
+	var out AdminFeedback
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		cur, err := scanAdminFeedback(tx.QueryRow(ctx, `SELECT `+adminFeedbackCols+` FROM public.feedbacks WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		status, tags, published := cur.Status, cur.Tags, cur.Published
+		if p.Status != nil {
+			if !slices.Contains(FeedbackStatuses, *p.Status) {
+				return ErrUnknownStatus
+			}
+			if !CanTransition(cur.Status, *p.Status) {
+				return ErrBadTransition
+			}
+			status = *p.Status
+		}
+		if p.Tags != nil {
+			tags = *p.Tags
+		}
+		if p.Published != nil {
+			published = *p.Published
+		}
+		if status == "spam" {
+			published = false
+		}
+
+		out, err = scanAdminFeedback(tx.QueryRow(ctx, `
+			UPDATE public.feedbacks
+			SET status = $2, tags = $3, published = $4, updated_at = now()
+			WHERE id = $1
+			RETURNING `+adminFeedbackCols, id, status, tags, published))
+		if err != nil {
+			return err
+		}
+		if status != cur.Status {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO public.feedback_notes(feedback_id, kind, author, body)
+				VALUES ($1, 'status', $2, $3)
+			`, id, actor, cur.Status+" → "+status)
+		}
+		return err
+	})
+	return out, err
+}
+
+type FeedbackNote struct {
+	ID        int64
+	Kind      string
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+func AddFeedbackNote(ctx context.Context, feedbackID int64, author, body string) (FeedbackNote, error) {
+	if pool == nil {
+		return FeedbackNote{}, ErrNotInitialized
+	}
+	n := FeedbackNote{Kind: "note", Author: author, Body: body}
+	err := pool.QueryRow(ctx, `
+		INSERT INTO public.feedback_notes(feedback_id, author, body)
+		SELECT id, $2, $3 FROM public.feedbacks WHERE id = $1
+		RETURNING id, created_at
+	`, feedbackID, author, body).Scan(&n.ID, &n.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return n, ErrNoFeedback
+	}
+	return n, err
+}
+
+// ListFeedbackNotes เก่าก่อน
+func ListFeedbackNotes(ctx context.Context, feedbackID int64) ([]FeedbackNote, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT id, kind, author, body, created_at FROM public.feedback_notes
+		WHERE feedback_id = $1
+		ORDER BY created_at, id
+	`, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []FeedbackNote
+	for rows.Next() {
+		var n FeedbackNote
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Author, &n.Body, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,76 @@
+// internal/db/feedback_attachments.go
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNoAttachment = errors.New("no attachment")
+
+// Attachment คือรูปประกอบ feedback (ตัวไฟล์อยู่ใน storage)
+type Attachment struct {
+	ID          int64
+	FeedbackID  int64
+	StorageKey  string
+	ThumbKey    string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	CreatedAt   time.Time
+}
+
+const attachmentCols = `id, feedback_id, storage_key, thumb_key, content_type, size_bytes, width, height, created_at`
+
+func scanAttachment(row pgx.Row) (Attachment, error) {
+	var a Attachment
+	err := row.Scan(&a.ID, &a.FeedbackID, &a.StorageKey, &a.ThumbKey, &a.ContentType,
+		&a.Size, &a.Width, &a.Height, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return a, ErrNoAttachment
+	}
+	return a, err
+}
+
+// ListFeedbackAttachments คืนรูปของ feedback หลายรายการในครั้งเดียว (key = feedback id)
+func ListFeedbackAttachments(ctx context.Context, feedbackIDs []int64) (map[int64][]Attachment, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	out := map[int64][]Attachment{}
+	if len(feedbackIDs) == 0 {
+		return out, nil
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT `+attachmentCols+` FROM public.feedback_attachments
+		WHERE feedback_id = ANY($1)
+		ORDER BY feedback_id, id
+	`, feedbackIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[a.FeedbackID] = append(out[a.FeedbackID], a)
+	}
+	return out, rows.Err()
+}
+
+// GetFeedbackAttachment: รูป id ต้องเป็นของ feedback นั้นจริง
+func GetFeedbackAttachment(ctx context.Context, feedbackID, id int64) (Attachment, error) {
+	if pool == nil {
+		return Attachment{}, ErrNotInitialized
+	}
+	return scanAttachment(pool.QueryRow(ctx, `
+		SELECT `+attachmentCols+` FROM public.feedback_attachments
+		WHERE id = $1 AND feedback_id = $2
+	`, id, feedbackID))
+}
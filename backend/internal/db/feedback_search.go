@@ -0,0 +1,102 @@
+// internal/db/feedback_search.go
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+// FeedbackHit คือผลค้นหาหนึ่งรายการ — Score ยิ่งมากยิ่งตรง (ใช้เป็น cursor ด้วย)
+type FeedbackHit struct {
+	AdminFeedback
+	Score int
+}
+
+// SearchTerms แยกคำค้นสำหรับจับแบบ substring (ตัดเครื่องหมายของ websearch: "..." -คำ or)
+// terms = คำที่ต้องมี, exclude = คำที่ขึ้นต้นด้วย - (ต้องไม่มี)
+func SearchTerms(q string) (terms, exclude []string) {
+	for _, t := range strings.Fields(q) {
+		if strings.EqualFold(t, "or") {
+			continue // ตัวเชื่อม ให้ tsquery จัดการ
+		}
+		neg := strings.HasPrefix(t, "-")
+		if t = strings.Trim(strings.TrimPrefix(t, "-"), `"`); t == "" {
+			continue
+		}
+		if neg {
+			exclude = append(exclude, t)
+		} else {
+			terms = append(terms, t)
+		}
+	}
+	return terms, exclude
+}
+
+// likePatterns: %คำ% สำหรับ ILIKE ALL/ANY
+func likePatterns(terms []string) []string {
+	out := make([]string, len(terms))
+	for i, t := range terms {
+		out[i] = "%" + likeEscape.Replace(t) + "%"
+	}
+	return out
+}
+
+// likeEscape ให้ % _ \ ในคำค้นเป็นตัวอักษรธรรมดา
+var likeEscape = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchFeedback ค้น name + message เรียงตามความตรง แล้วใหม่ก่อน
+// ตรงเมื่อ tsquery (websearch syntax, stem ภาษาอังกฤษ) ตรง หรือทุกคำเป็น substring (ไทย/คำไม่ครบ)
+// และต้องไม่มีคำ -คำ เป็น substring (ไม่งั้นทาง substring จะข้ามคำที่ไม่เอาไป)
+// Score = (ts_rank + word_similarity) × 10^6
+func SearchFeedback(ctx context.Context, q string, f FeedbackFilter, cur *Cursor, limit int) ([]FeedbackHit, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	terms, exclude := SearchTerms(q)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	cond, order, cargs := scoreKeyset(cur, 11, OrderDesc)
+	rows, err := pool.Query(ctx, `
+		SELECT `+adminFeedbackCols+`, score
+		FROM (
+			SELECT fb.*,
+			       round((ts_rank(fb.search_tsv, q.tsq) + word_similarity($1, fb.name || ' ' || fb.message)) * 1000000)::int AS score
+			FROM public.feedbacks fb, websearch_to_tsquery('english', $1) AS q(tsq)
+			WHERE (fb.search_tsv @@ q.tsq OR (fb.name || ' ' || fb.message) ILIKE ALL($2))
+			  AND NOT (fb.name || ' ' || fb.message) ILIKE ANY($11)
+			  AND ($3 = '' OR fb.status = $3)
+			  AND ($4 = '' OR fb.source = $4)
+			  AND ($5 = '' OR $5 = ANY(fb.tags))
+			  AND ($6::timestamptz IS NULL OR fb.created_at >= $6)
+			  AND ($7::timestamptz IS NULL OR fb.created_at <  $7)
+			  AND ($8 = '' OR fb.context->>'request_id' = $8)
+			  AND ($9 = '' OR fb.context->>'room_code' = $9)
+		) hits
+		WHERE `+cond+`
+		ORDER BY `+order+`
+		LIMIT $10
+	`, append([]any{q, likePatterns(terms), f.Status, f.Source, f.Tag, nullTime(f.Range.From), nullTime(f.Range.To),
+		f.RequestID, f.RoomCode, limit, likePatterns(exclude)}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []FeedbackHit
+	for rows.Next() {
+		var h FeedbackHit
+		if err := rows.Scan(&h.ID, &h.Name, &h.Contact, &h.Message, &h.Source, &h.CreatedAt,
+			&h.PlayerID, &h.Status, &h.Tags, &h.Published, &h.UpdatedAt, &h.Context, &h.Score); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
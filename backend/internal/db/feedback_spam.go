@@ -0,0 +1,82 @@
+// internal/db/feedback_spam.go
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// RecentDuplicateFeedback: ผู้ส่งคนเดิมส่ง hash เดียวกันภายใน window ไหม
+// ผู้ส่ง = player id ถ้ามี ไม่งั้น IP (คนละคนส่งข้อความสั้นเหมือนกัน เช่น "เกมค้าง" ไม่นับ)
+func RecentDuplicateFeedback(ctx context.Context, hash, playerID, ip string, window time.Duration) (bool, error) {
+	if pool == nil {
+		return false, ErrNotInitialized
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM public.feedbacks
+			WHERE message_hash = $1 AND created_at > now() - make_interval(secs => $2)
+			  AND CASE WHEN $3 <> '' THEN player_id = $3
+			           ELSE player_id IS NULL AND ip = $4 END
+		)
+	`, hash, window.Seconds(), playerID, ip).Scan(&ok)
+	return ok, err
+}
+
+type FeedbackRejection struct {
+	ID        int64
+	Reason    string
+	IP        string
+	PlayerID  string
+	Name      string
+	Contact   string
+	Message   string
+	Source    string
+	CreatedAt time.Time
+}
+
+func InsertFeedbackRejection(ctx context.Context, f FeedbackRejection) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO public.feedback_rejections(reason, ip, player_id, name, contact, message, source)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	`, f.Reason, f.IP, f.PlayerID, f.Name, f.Contact, f.Message, f.Source)
+	return err
+}
+
+// ListFeedbackRejections ใหม่สุดก่อน ต่อจาก cur (reason ว่าง = ทุกเหตุผล)
+func ListFeedbackRejections(ctx context.Context, reason string, cur *Cursor, limit int) ([]FeedbackRejection, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	cond, order, cargs := createdKeyset(cur, 2)
+	rows, err := pool.Query(ctx, `
+		SELECT id, reason, ip, COALESCE(player_id, ''), name, contact, message, source, created_at
+		FROM public.feedback_rejections
+		WHERE ($1 = '' OR reason = $1) AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $2
+	`, append([]any{reason, limit}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []FeedbackRejection
+	for rows.Next() {
+		var f FeedbackRejection
+		if err := rows.Scan(&f.ID, &f.Reason, &f.IP, &f.PlayerID, &f.Name, &f.Contact, &f.Message, &f.Source, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
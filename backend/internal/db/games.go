@@ -0,0 +1,75 @@
+// internal/db/games.go
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNoGame = errors.New("game not found")
+
+// ลำดับคะแนนของเกม
+const (
+	OrderDesc = "desc" // มากดีกว่า
+	OrderAsc  = "asc"  // น้อยดีกว่า (เช่น เวลาที่ใช้)
+)
+
+type Game struct {
+	ID              string
+	DisplayName     string
+	MinScore        int
+	MaxScore        int
+	ScoreOrder      string
+	RequiresReceipt bool
+	CreatedAt       time.Time
+}
+
+const gameCols = `id, display_name, min_score, max_score, score_order, requires_receipt, created_at`
+
+func scanGame(row pgx.Row) (Game, error) {
+	var g Game
+	err := row.Scan(&g.ID, &g.DisplayName, &g.MinScore, &g.MaxScore, &g.ScoreOrder, &g.RequiresReceipt, &g.CreatedAt)
+	return g, err
+}
+
+func GetGame(ctx context.Context, id string) (Game, error) {
+	if pool == nil {
+		return Game{}, ErrNotInitialized
+	}
+	g, err := scanGame(pool.QueryRow(ctx, `SELECT `+gameCols+` FROM public.games WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Game{}, ErrNoGame
+	}
+	return g, err
+}
+
+func ListGames(ctx context.Context) ([]Game, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `SELECT `+gameCols+` FROM public.games ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Game
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+// scoreKey คือค่าที่ใช้เรียง leaderboard: มากก่อนเสมอ (asc กลับเครื่องหมายคะแนน)
+func scoreKey(order string) string {
+	if order == OrderAsc {
+		return "(-score)"
+	}
+	return "score"
+}
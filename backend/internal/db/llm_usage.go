@@ -0,0 +1,81 @@
+// internal/db/llm_usage.go
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// LLMUsage คือยอดรวมของหนึ่งวัน/provider/model/use case
+type LLMUsage struct {
+	Day          time.Time // วันที่ (เที่ยงคืน UTC ของวันนั้น)
+	Provider     string
+	Model        string
+	UseCase      string
+	Requests     int
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// AddLLMUsage บวกยอดเข้าแถวของวันนั้น (Requests ของ u = จำนวนที่บวกเพิ่ม)
+func AddLLMUsage(ctx context.Context, u LLMUsage) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO public.llm_usage_daily AS d
+			(day, provider, model, use_case, requests, input_tokens, output_tokens, cost_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (day, provider, model, use_case) DO UPDATE SET
+			requests      = d.requests + EXCLUDED.requests,
+			input_tokens  = d.input_tokens + EXCLUDED.input_tokens,
+			output_tokens = d.output_tokens + EXCLUDED.output_tokens,
+			cost_usd      = d.cost_usd + EXCLUDED.cost_usd,
+			updated_at    = now()
+	`, u.Day, u.Provider, u.Model, u.UseCase, u.Requests, u.InputTokens, u.OutputTokens, u.CostUSD)
+	return err
+}
+
+// LLMUsageTotal: token (เข้า+ออก) และค่าใช้จ่ายรวมของวัน ไม่นับ provider ใน free (เช่น mock)
+func LLMUsageTotal(ctx context.Context, day time.Time, free ...string) (tokens int64, cost float64, err error) {
+	if pool == nil {
+		return 0, 0, ErrNotInitialized
+	}
+	if free == nil {
+		free = []string{}
+	}
+	err = pool.QueryRow(ctx, `
+		SELECT COALESCE(sum(input_tokens + output_tokens), 0)::bigint, COALESCE(sum(cost_usd), 0)
+		FROM public.llm_usage_daily
+		WHERE day = $1 AND NOT (provider = ANY($2))
+	`, day, free).Scan(&tokens, &cost)
+	return tokens, cost, err
+}
+
+// ListLLMUsage คืนยอดตั้งแต่วัน from ใหม่สุดก่อน
+func ListLLMUsage(ctx context.Context, from time.Time) ([]LLMUsage, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT day, provider, model, use_case, requests, input_tokens, output_tokens, cost_usd
+		FROM public.llm_usage_daily
+		WHERE day >= $1
+		ORDER BY day DESC, provider, model, use_case
+	`, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []LLMUsage
+	for rows.Next() {
+		var u LLMUsage
+		if err := rows.Scan(&u.Day, &u.Provider, &u.Model, &u.UseCase, &u.Requests,
+			&u.InputTokens, &u.OutputTokens, &u.CostUSD); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
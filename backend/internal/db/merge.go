@@ -0,0 +1,186 @@
+// internal/db/merge.go
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNotGuest = errors.New("player is not a guest")
+
+// UpgradeGuest เปลี่ยน guest เป็นบัญชีโดยใช้ id เดิม — คะแนน/ประวัติตามมาเองไม่ต้องย้าย
+func UpgradeGuest(ctx context.Context, guestID, username, displayName, passwordHash string) (Player, error) {
+	if pool == nil {
+		return Player{}, ErrNotInitialized
+	}
+	var p Player
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			UPDATE public.players
+			SET kind = 'registered', display_name = $2, last_seen_at = now()
+			WHERE id = $1 AND kind = 'guest'
+			RETURNING id, display_name, kind, created_at
+		`, guestID, displayName).Scan(&p.ID, &p.DisplayName, &p.Kind, &p.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotGuest
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO public.accounts(player_id, username, password_hash)
+			VALUES ($1, $2, $3)
+		`, guestID, username, passwordHash); err != nil {
+			return err
+		}
+		// ชื่อในคะแนนเก่าให้ตรงกับชื่อปัจจุบัน (leaderboard แบบ best รวมตามชื่อ)
+		_, err = tx.Exec(ctx, `UPDATE public.scores SET name = $2 WHERE player_id = $1`, guestID, displayName)
+		return err
+	})
+	if name, ok := uniqueViolation(err); ok {
+		if name == "uq_accounts_username" {
+			return Player{}, ErrUsernameTaken
+		}
+		return Player{}, ErrNameTaken
+	}
+	return p, err
+}
+
+// mergedTables: ตารางที่ MergeGuest ย้าย player_id มาเป็นของบัญชีทั้งหมด
+// (scores, quiz_runs, player_badges จัดการแยกเพราะต้องนับ/กันซ้ำ)
+var mergedTables = []string{
+	"party_results",
+	"chat_conversations",
+	"feedbacks",
+	"feedback_rejections",
+	"season_standings",
+	"moderation_flags",
+	"webhook_deliveries",
+}
+
+type MergeResult struct {
+	Account           Player
+	GuestName         string
+	ScoresMoved       int64
+	DuplicatesRemoved int64
+	QuizRunsMoved     int64
+}
+
+// MergeGuest ย้ายทุกอย่างของ guestID ไปที่ accountID แล้วลบ guest ทิ้ง (ทั้งหมดใน transaction เดียว)
+//
+//   - keepGuestName=true → บัญชีใช้ชื่อของ guest แทน (ชื่อ guest ว่างลงพร้อมกับที่ลบ guest)
+//   - คะแนนที่ซ้ำกันจริง ๆ (เกม/คะแนนเดียวกัน ส่งภายในวินาทีเดียวกันจากทั้งสองตัวตน) เหลือแถวเดียว
+//   - คะแนนอื่นย้ายมาทั้งหมด leaderboard แบบ best เลือกคะแนนดีสุดให้เอง (เท่ากันเอาอันที่ได้ก่อน)
+func MergeGuest(ctx context.Context, guestID, accountID string, keepGuestName bool) (MergeResult, error) {
+	if pool == nil {
+		return MergeResult{}, ErrNotInitialized
+	}
+	var res MergeResult
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		// ล็อกทั้งสองแถวตามลำดับ id กัน deadlock เวลามี merge ชนกัน
+		rows, err := tx.Query(ctx, `
+			SELECT id, display_name, kind, created_at
+			FROM public.players
+			WHERE id IN ($1, $2)
+			ORDER BY id
+			FOR UPDATE
+		`, guestID, accountID)
+		if err != nil {
+			return err
+		}
+		var guest, acc *Player
+		for rows.Next() {
+			var p Player
+			if err := rows.Scan(&p.ID, &p.DisplayName, &p.Kind, &p.CreatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+			if p.ID == guestID {
+				guest = &p
+			} else {
+				acc = &p
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if guest == nil || acc == nil {
+			return ErrNoPlayer
+		}
+		if guest.Kind != "guest" {
+			return ErrNotGuest
+		}
+		res.GuestName = guest.DisplayName
+
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM public.scores g
+			USING public.scores a
+			WHERE g.player_id = $1 AND a.player_id = $2
+			  AND g.gamename = a.gamename AND g.score = a.score
+			  AND date_trunc('second', g.created_at) = date_trunc('second', a.created_at)
+		`, guestID, accountID)
+		if err != nil {
+			return err
+		}
+		res.DuplicatesRemoved = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `UPDATE public.scores SET player_id = $2 WHERE player_id = $1`, guestID, accountID)
+		if err != nil {
+			return err
+		}
+		res.ScoresMoved = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `UPDATE public.quiz_runs SET player_id = $2 WHERE player_id = $1`, guestID, accountID)
+		if err != nil {
+			return err
+		}
+		res.QuizRunsMoved = tag.RowsAffected()
+
+		// ตารางอื่นที่ผูก player_id — ต้องย้ายก่อนลบ guest ไม่งั้น FK กลายเป็น NULL
+		// แล้ว export/ลบข้อมูลของบัญชีจะหาไม่เจอ (เช่น feedback ที่มีช่องทางติดต่อและ IP)
+		for _, table := range mergedTables {
+			if _, err := tx.Exec(ctx, `UPDATE public.`+table+` SET player_id = $2 WHERE player_id = $1`, guestID, accountID); err != nil {
+				return fmt.Errorf("merge %s: %w", table, err)
+			}
+		}
+
+		// badge ที่บัญชีมีอยู่แล้วคงวันที่เดิม ที่เหลือย้ายมาจาก guest
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO public.player_badges(player_id, badge_id, unlocked_at)
+			SELECT $2, badge_id, unlocked_at FROM public.player_badges WHERE player_id = $1
+			ON CONFLICT DO NOTHING
+		`, guestID, accountID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM public.players WHERE id = $1`, guestID); err != nil {
+			return err
+		}
+
+		name := acc.DisplayName
+		if keepGuestName {
+			name = guest.DisplayName
+		}
+		if err := tx.QueryRow(ctx, `
+			UPDATE public.players SET display_name = $2, last_seen_at = now()
+			WHERE id = $1
+			RETURNING id, display_name, kind, created_at
+		`, accountID, name).Scan(&res.Account.ID, &res.Account.DisplayName, &res.Account.Kind, &res.Account.CreatedAt); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE public.scores SET name = $2 WHERE player_id = $1`, accountID, name); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO public.player_merges(guest_id, account_id, guest_name, scores_moved, duplicates_removed, quiz_runs_moved)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, guestID, accountID, guest.DisplayName, res.ScoresMoved, res.DuplicatesRemoved, res.QuizRunsMoved)
+		return err
+	})
+	return res, err
+}
@@ -0,0 +1,26 @@
+// internal/db/moderation.go
+package db
+
+import "context"
+
+type ModerationFlag struct {
+	Field    string
+	Source   string
+	Content  string
+	Terms    []string
+	Action   string
+	PlayerID string // "" = ไม่ระบุตัวตน
+	IP       string
+}
+
+// InsertModerationFlag บันทึกข้อความที่ต้องให้ admin ตรวจ
+func InsertModerationFlag(ctx context.Context, f ModerationFlag) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO public.moderation_flags(field, source, content, terms, action, player_id, ip)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+	`, f.Field, f.Source, f.Content, f.Terms, f.Action, f.PlayerID, f.IP)
+	return err
+}
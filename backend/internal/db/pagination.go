@@ -0,0 +1,55 @@
+// internal/db/pagination.go
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Cursor คือตำแหน่ง keyset ของแถวสุดท้าย (หรือแรก) ในหน้าที่แล้ว
+// nil = หน้าแรก, Before = ขอหน้าก่อนหน้าตำแหน่งนี้
+type Cursor struct {
+	Score     int       `json:"s,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+	Before    bool      `json:"b,omitempty"`
+}
+
+// scoreKeyset: ลำดับ leaderboard คือ key DESC, created_at ASC, id ASC (key = scoreKey(order))
+// n = เลข placeholder ตัวสุดท้ายที่ใช้ไปแล้วใน query
+func scoreKeyset(cur *Cursor, n int, order string) (cond, orderBy string, args []any) {
+	key := scoreKey(order)
+	if cur == nil {
+		return "TRUE", key + " DESC, created_at ASC, id ASC", nil
+	}
+	ck := cur.Score
+	if order == OrderAsc {
+		ck = -ck
+	}
+	args = []any{ck, cur.CreatedAt, cur.ID}
+	if cur.Before {
+		cond = fmt.Sprintf(`(%[4]s > $%[1]d OR (%[4]s = $%[1]d AND (created_at < $%[2]d OR (created_at = $%[2]d AND id < $%[3]d))))`, n+1, n+2, n+3, key)
+		return cond, key + " ASC, created_at DESC, id DESC", args
+	}
+	cond = fmt.Sprintf(`(%[4]s < $%[1]d OR (%[4]s = $%[1]d AND (created_at > $%[2]d OR (created_at = $%[2]d AND id > $%[3]d))))`, n+1, n+2, n+3, key)
+	return cond, key + " DESC, created_at ASC, id ASC", args
+}
+
+// createdKeyset: ลำดับใหม่สุดก่อน created_at DESC, id DESC
+func createdKeyset(cur *Cursor, n int) (cond, order string, args []any) {
+	if cur == nil {
+		return "TRUE", "created_at DESC, id DESC", nil
+	}
+	args = []any{cur.CreatedAt, cur.ID}
+	if cur.Before {
+		return fmt.Sprintf(`(created_at, id) > ($%d, $%d)`, n+1, n+2), "created_at ASC, id ASC", args
+	}
+	return fmt.Sprintf(`(created_at, id) < ($%d, $%d)`, n+1, n+2), "created_at DESC, id DESC", args
+}
+
+// query ที่ขอหน้าก่อนหน้าเรียงกลับด้าน ต้องกลับลำดับก่อนส่งออก
+func reverse[T any](s []T) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,161 @@
+// internal/db/players.go
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNoPlayer  = errors.New("no player")
+	ErrNameTaken = errors.New("display name taken")
+)
+
+type Player struct {
+	ID          string
+	DisplayName string
+	Kind        string
+	CreatedAt   time.Time
+}
+
+func isUniqueViolation(err error) bool {
+	_, ok := uniqueViolation(err)
+	return ok
+}
+
+// uniqueViolation คืนชื่อ index/constraint ที่ชน (ใช้แยกว่าชื่อไหนซ้ำ)
+func uniqueViolation(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// CreateGuestPlayer จองชื่อ name ให้ player ใหม่ ชื่อซ้ำคืน ErrNameTaken
+func CreateGuestPlayer(ctx context.Context, id, name string) (Player, error) {
+	if pool == nil {
+		return Player{}, ErrNotInitialized
+	}
+	p := Player{ID: id, DisplayName: name, Kind: "guest"}
+	err := pool.QueryRow(ctx, `
+		INSERT INTO public.players(id, display_name, kind)
+		VALUES ($1, $2, 'guest')
+		RETURNING created_at
+	`, id, name).Scan(&p.CreatedAt)
+	if isUniqueViolation(err) {
+		return Player{}, ErrNameTaken
+	}
+	return p, err
+}
+
+// GetPlayer ดึง player พร้อมอัปเดต last_seen_at
+func GetPlayer(ctx context.Context, id string) (Player, error) {
+	if pool == nil {
+		return Player{}, ErrNotInitialized
+	}
+	var p Player
+	err := pool.QueryRow(ctx, `
+		UPDATE public.players SET last_seen_at = now()
+		WHERE id = $1
+		RETURNING id, display_name, kind, created_at
+	`, id).Scan(&p.ID, &p.DisplayName, &p.Kind, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, ErrNoPlayer
+	}
+	return p, err
+}
+
+// LookupPlayer ดึง player แบบอ่านอย่างเดียว (ดูโปรไฟล์คนอื่น ไม่นับเป็นการใช้งาน)
+func LookupPlayer(ctx context.Context, id string) (Player, error) {
+	if pool == nil {
+		return Player{}, ErrNotInitialized
+	}
+	var p Player
+	err := pool.QueryRow(ctx, `
+		SELECT id, display_name, kind, created_at FROM public.players WHERE id = $1
+	`, id).Scan(&p.ID, &p.DisplayName, &p.Kind, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, ErrNoPlayer
+	}
+	return p, err
+}
+
+// IsNameReserved: ชื่อนี้เป็นของ player คนใดคนหนึ่งแล้วหรือยัง
+func IsNameReserved(ctx context.Context, name string) (bool, error) {
+	if pool == nil {
+		return false, ErrNotInitialized
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM public.players WHERE lower(display_name) = lower($1))
+	`, name).Scan(&ok)
+	return ok, err
+}
+
+// ===== Quiz runs =====
+
+type QuizRun struct {
+	ID       string // quiz id ที่อยู่ใน token
+	PlayerID string
+	QuizID   int64
+	Mode     string
+	Tier     int
+	Category string
+	Seconds  int
+}
+
+func InsertQuizRun(ctx context.Context, run QuizRun) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO public.quiz_runs(id, player_id, quiz_id, mode, tier, category, seconds)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING
+	`, run.ID, run.PlayerID, run.QuizID, run.Mode, run.Tier, run.Category, run.Seconds)
+	return err
+}
+
+// MarkQuizRunSolved บันทึกว่าตอบถูก (ครั้งแรกเท่านั้น) — true = เพิ่งตอบถูกครั้งนี้
+func MarkQuizRunSolved(ctx context.Context, id, playerID string) (bool, error) {
+	if pool == nil {
+		return false, ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `
+		UPDATE public.quiz_runs SET solved = TRUE, solved_at = now()
+		WHERE id = $1 AND player_id = $2 AND NOT solved
+	`, id, playerID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RecordQuizRunHint: ขอ hint ข้อ index แล้ว (ขอซ้ำไม่นับเพิ่ม)
+func RecordQuizRunHint(ctx context.Context, id, playerID string, index int) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		UPDATE public.quiz_runs SET hints_used = GREATEST(hints_used, $3)
+		WHERE id = $1 AND player_id = $2
+	`, id, playerID, index)
+	return err
+}
+
+// RecordQuizRunQuestion นับคำถามที่ถามผู้ช่วย AI ในคำนี้ (หนึ่งคำถาม = hint หนึ่งครั้ง)
+func RecordQuizRunQuestion(ctx context.Context, id, playerID string) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	_, err := pool.Exec(ctx, `
+		UPDATE public.quiz_runs SET ai_questions = ai_questions + 1
+		WHERE id = $1 AND player_id = $2
+	`, id, playerID)
+	return err
+}
@@ -0,0 +1,197 @@
+// internal/db/privacy.go
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ข้อมูลส่วนตัวของผู้เล่นแยกเป็นหมวด (ชื่อหมวด → query ที่รับ $1 = player id)
+var exportSections = []struct {
+	name  string
+	query string
+}{
+	{"account", `SELECT username, created_at, updated_at FROM public.accounts WHERE player_id = $1`},
+	{"sessions", `SELECT id, user_agent, created_at, last_used_at, expires_at, revoked_at
+		FROM public.sessions WHERE player_id = $1 ORDER BY created_at`},
+	{"scores", `SELECT id, name, score, gamename, season_id, created_at
+		FROM public.scores WHERE player_id = $1 ORDER BY created_at`},
+	{"season_standings", `SELECT season_id, gamename, rank, name, score, achieved_at
+		FROM public.season_standings WHERE player_id = $1 ORDER BY season_id, gamename`},
+	{"quiz_runs", `SELECT id, quiz_id, mode, tier, category, seconds, hints_used, ai_questions, solved, created_at, solved_at
+		FROM public.quiz_runs WHERE player_id = $1 ORDER BY created_at`},
+	{"party_results", `SELECT room_code, score, won, finished_at
+		FROM public.party_results WHERE player_id = $1 ORDER BY finished_at`},
+	{"badges", `SELECT badge_id, unlocked_at FROM public.player_badges WHERE player_id = $1 ORDER BY unlocked_at`},
+	{"feedbacks", `SELECT id, name, contact, message, source, ip, context, created_at
+		FROM public.feedbacks WHERE player_id = $1 ORDER BY created_at`},
+	{"feedback_attachments", `SELECT a.id, a.feedback_id, a.content_type, a.size_bytes, a.width, a.height, a.created_at
+		FROM public.feedback_attachments a JOIN public.feedbacks f ON f.id = a.feedback_id
+		WHERE f.player_id = $1 ORDER BY a.created_at`},
+	{"feedback_rejections", `SELECT reason, ip, name, contact, message, source, created_at
+		FROM public.feedback_rejections WHERE player_id = $1 ORDER BY created_at`},
+	{"moderation_flags", `SELECT field, source, content, terms, action, ip, status, created_at
+		FROM public.moderation_flags WHERE player_id = $1 ORDER BY created_at`},
+	{"chat_conversations", `SELECT id, use_case, title, created_at, updated_at
+		FROM public.chat_conversations WHERE player_id = $1 ORDER BY created_at`},
+	{"chat_messages", `SELECT m.conversation_id, m.role, m.content, m.created_at
+		FROM public.chat_messages m JOIN public.chat_conversations c ON c.id = m.conversation_id
+		WHERE c.player_id = $1 ORDER BY m.created_at, m.id`},
+	{"merges", `SELECT guest_id, guest_name, scores_moved, quiz_runs_moved, merged_at
+		FROM public.player_merges WHERE account_id = $1 ORDER BY merged_at`},
+}
+
+// ExportPlayerData คืนทุกอย่างที่เก็บเกี่ยวกับ player เป็น JSON แยกหมวด
+func ExportPlayerData(ctx context.Context, playerID string) (map[string]json.RawMessage, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	out := map[string]json.RawMessage{}
+	var player json.RawMessage
+	err := pool.QueryRow(ctx, `
+		SELECT row_to_json(p) FROM (
+			SELECT id, display_name, kind, created_at, last_seen_at FROM public.players WHERE id = $1
+		) p
+	`, playerID).Scan(&player)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoPlayer
+	}
+	if err != nil {
+		return nil, err
+	}
+	out["player"] = player
+
+	for _, sec := range exportSections {
+		var raw json.RawMessage
+		if err := pool.QueryRow(ctx,
+			`SELECT COALESCE(json_agg(t), '[]'::json) FROM (`+sec.query+`) t`, playerID,
+		).Scan(&raw); err != nil {
+			return nil, err
+		}
+		out[sec.name] = raw
+	}
+	return out, nil
+}
+
+type Erasure struct {
+	PlayerID  string
+	Actor     string // player id ตัวเอง หรือชื่อ admin
+	ActorKind string // "player" | "admin"
+	Contact   string // admin: ลบ feedback ที่ไม่ระบุตัวตนแต่ใช้ contact นี้ด้วย
+	Alias     string // ชื่อที่ใช้แทนในคะแนน/อันดับที่เก็บไว้
+}
+
+type ErasureResult struct {
+	SessionIDs []string         // handler ใช้ revoke access token ที่ยังไม่หมดอายุ
+	FileKeys   []string         // ไฟล์ใน storage ที่ handler ต้องลบตามหลัง commit
+	Counts     map[string]int64 // จำนวนแถวต่อตาราง (เก็บใน audit ด้วย)
+}
+
+// ErasePlayer ลบ/ทำให้ไม่ระบุตัวตนทุกอย่างของ player ใน transaction เดียว แล้วบันทึก audit
+//   - scores และ season_standings เก็บไว้ (leaderboard ไม่เพี้ยน) แต่เปลี่ยนชื่อเป็น Alias และตัด player_id
+//     รวมถึงแถวที่ไม่มี player_id แต่ใช้ชื่อเดียวกันก่อนสร้าง player (คะแนนยุคก่อนมีระบบ player)
+//     หลังจากนั้นชื่อถูกจองแล้ว คนอื่นส่งคะแนนด้วยชื่อนี้ไม่ได้
+//   - feedback (รวมรูปแนบ), moderation flag, quiz run, ผล party, badge, บทสนทนา AI, บัญชี, session ลบทิ้ง
+//   - webhook ที่ยังค้างในคิวของ feedback เหล่านั้นลบด้วย (รวม feedback ที่ตรงกับ Contact)
+func ErasePlayer(ctx context.Context, e Erasure) (ErasureResult, error) {
+	if pool == nil {
+		return ErasureResult{}, ErrNotInitialized
+	}
+	res := ErasureResult{Counts: map[string]int64{}}
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		var (
+			name    string
+			created time.Time
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT display_name, created_at FROM public.players WHERE id = $1 FOR UPDATE
+		`, e.PlayerID).Scan(&name, &created)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNoPlayer
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `SELECT id FROM public.sessions WHERE player_id = $1`, e.PlayerID)
+		if err != nil {
+			return err
+		}
+		res.SessionIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+
+		// แถวรูปแนบหายไปกับ feedback (ON DELETE CASCADE) แต่ตัวไฟล์ต้องลบเอง
+		rows, err = tx.Query(ctx, `
+			SELECT a.storage_key, a.thumb_key
+			FROM public.feedback_attachments a JOIN public.feedbacks f ON f.id = a.feedback_id
+			WHERE f.player_id = $1 OR ($2 <> '' AND lower(f.contact) = lower($2))
+		`, e.PlayerID, e.Contact)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var key, thumb string
+			if err := rows.Scan(&key, &thumb); err != nil {
+				rows.Close()
+				return err
+			}
+			res.FileKeys = append(res.FileKeys, key, thumb)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		res.Counts["feedback_attachments"] = int64(len(res.FileKeys) / 2)
+
+		steps := []struct {
+			name string
+			sql  string
+			args []any
+		}{
+			{"scores", `UPDATE public.scores SET name = $2, player_id = NULL WHERE player_id = $1`, []any{e.PlayerID, e.Alias}},
+			{"season_standings", `UPDATE public.season_standings SET name = $2, player_id = NULL WHERE player_id = $1`, []any{e.PlayerID, e.Alias}},
+			{"legacy_scores", `UPDATE public.scores SET name = $2
+				WHERE player_id IS NULL AND name = $1 AND created_at < $3`, []any{name, e.Alias, created}},
+			{"legacy_season_standings", `UPDATE public.season_standings SET name = $2
+				WHERE player_id IS NULL AND name = $1 AND achieved_at < $3`, []any{name, e.Alias, created}},
+			// ต้องก่อนลบ feedback: webhook ของ feedback แบบไม่ระบุตัวตนหาได้จาก id ใน payload เท่านั้น
+			{"webhook_deliveries", `DELETE FROM public.webhook_deliveries
+				WHERE player_id = $1 OR ($2 <> '' AND event = 'feedback.created' AND payload->'data'->>'id' IN (
+					SELECT id::text FROM public.feedbacks WHERE lower(contact) = lower($2)))`, []any{e.PlayerID, e.Contact}},
+			{"feedbacks", `DELETE FROM public.feedbacks
+				WHERE player_id = $1 OR ($2 <> '' AND lower(contact) = lower($2))`, []any{e.PlayerID, e.Contact}},
+			{"feedback_rejections", `DELETE FROM public.feedback_rejections
+				WHERE player_id = $1 OR ($2 <> '' AND lower(contact) = lower($2))`, []any{e.PlayerID, e.Contact}},
+			{"moderation_flags", `DELETE FROM public.moderation_flags WHERE player_id = $1`, []any{e.PlayerID}},
+			{"quiz_runs", `DELETE FROM public.quiz_runs WHERE player_id = $1`, []any{e.PlayerID}},
+			{"party_results", `DELETE FROM public.party_results WHERE player_id = $1`, []any{e.PlayerID}},
+			{"badges", `DELETE FROM public.player_badges WHERE player_id = $1`, []any{e.PlayerID}},
+			{"chat_conversations", `DELETE FROM public.chat_conversations WHERE player_id = $1`, []any{e.PlayerID}}, // ข้อความตาม (CASCADE)
+			{"merges", `DELETE FROM public.player_merges WHERE account_id = $1`, []any{e.PlayerID}},
+			{"sessions", `DELETE FROM public.sessions WHERE player_id = $1`, []any{e.PlayerID}},
+			{"accounts", `DELETE FROM public.accounts WHERE player_id = $1`, []any{e.PlayerID}},
+			{"players", `DELETE FROM public.players WHERE id = $1`, []any{e.PlayerID}},
+		}
+		for _, st := range steps {
+			tag, err := tx.Exec(ctx, st.sql, st.args...)
+			if err != nil {
+				return err
+			}
+			res.Counts[st.name] = tag.RowsAffected()
+		}
+
+		counts, _ := json.Marshal(res.Counts)
+		_, err = tx.Exec(ctx, `
+			INSERT INTO public.data_erasures(player_id, actor, actor_kind, counts)
+			VALUES ($1, $2, $3, $4)
+		`, e.PlayerID, e.Actor, e.ActorKind, counts)
+		return err
+	})
+	return res, err
+}
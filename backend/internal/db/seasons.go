@@ -0,0 +1,268 @@
+// internal/db/seasons.go
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNoSeason = errors.New("season not found")
+
+type Season struct {
+	ID         int64
+	Name       string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	ArchivedAt *time.Time
+}
+
+const seasonCols = `id, name, starts_at, ends_at, archived_at`
+
+func scanSeason(row pgx.Row) (Season, error) {
+	var s Season
+	err := row.Scan(&s.ID, &s.Name, &s.StartsAt, &s.EndsAt, &s.ArchivedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, ErrNoSeason
+	}
+	return s, err
+}
+
+// CurrentSeason คือ season ที่ครอบเวลาปัจจุบัน (ไม่มี = ErrNoSeason)
+func CurrentSeason(ctx context.Context) (Season, error) {
+	if pool == nil {
+		return Season{}, ErrNotInitialized
+	}
+	return scanSeason(pool.QueryRow(ctx, `
+		SELECT `+seasonCols+` FROM public.seasons
+		WHERE starts_at <= now() AND now() < ends_at
+		ORDER BY starts_at DESC
+		LIMIT 1
+	`))
+}
+
+func GetSeason(ctx context.Context, id int64) (Season, error) {
+	if pool == nil {
+		return Season{}, ErrNotInitialized
+	}
+	return scanSeason(pool.QueryRow(ctx, `SELECT `+seasonCols+` FROM public.seasons WHERE id = $1`, id))
+}
+
+// ListSeasons ใหม่สุดก่อน
+func ListSeasons(ctx context.Context) ([]Season, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `SELECT `+seasonCols+` FROM public.seasons ORDER BY starts_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Season
+	for rows.Next() {
+		s, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// SeasonPlan คำนวณ season ถัดไป: last = season ล่าสุด (nil = ยังไม่มีเลย), count = จำนวน season ที่มี
+type SeasonPlan func(last *Season, count int, now time.Time) (name string, start, end time.Time)
+
+type RolloverResult struct {
+	Archived []Season // season ที่เพิ่ง archive
+	Created  *Season  // season ใหม่ (nil = มี season ปัจจุบันและ season ถัดไปอยู่แล้ว)
+}
+
+// rolloverLockKey: advisory lock กันหลาย instance หมุน season พร้อมกัน
+const rolloverLockKey = 724038
+
+// seasonLookahead: season ล่าสุดจะจบภายในช่วงนี้ = เปิด season ถัดไปรอไว้เลย (status upcoming)
+// ขอบ season จึงต่อกันพอดี ไม่มีช่วงที่ไม่มี season ปัจจุบันระหว่างรอ job รอบถัดไป
+const seasonLookahead = 24 * time.Hour
+
+// RolloverSeasons เปิด season ใหม่ถ้าไม่มี season ที่ครอบ now หรือ season ล่าสุดใกล้จบ,
+// ใส่ season ให้คะแนนที่ยังไม่มี season (บันทึกตอนไม่มี season ปัจจุบัน เช่น job ไม่ได้รัน)
+// แล้ว archive season ที่จบแล้ว ทั้งหมดใน transaction เดียว ถ้า instance อื่นกำลังทำอยู่ก็ข้ามไป (ผลว่าง)
+func RolloverSeasons(ctx context.Context, now time.Time, plan SeasonPlan) (RolloverResult, error) {
+	if pool == nil {
+		return RolloverResult{}, ErrNotInitialized
+	}
+	var res RolloverResult
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, rolloverLockKey).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		created, err := ensureNextSeason(ctx, tx, now, plan)
+		if err != nil {
+			return err
+		}
+		res.Created = created
+
+		// ก่อน archive: คะแนนช่วงที่ไม่มี season ต้องได้เข้าอันดับสุดท้ายด้วย
+		if _, err := tx.Exec(ctx, `
+			UPDATE public.scores sc SET season_id = s.id
+			FROM public.seasons s
+			WHERE sc.season_id IS NULL
+			  AND sc.created_at >= s.starts_at AND sc.created_at < s.ends_at
+			  AND s.archived_at IS NULL
+		`); err != nil {
+			return fmt.Errorf("backfill score seasons: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, `
+			SELECT `+seasonCols+` FROM public.seasons
+			WHERE ends_at <= $1 AND archived_at IS NULL
+			ORDER BY starts_at
+		`, now)
+		if err != nil {
+			return err
+		}
+		var ended []Season
+		for rows.Next() {
+			s, err := scanSeason(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			ended = append(ended, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, s := range ended {
+			if err := archiveSeason(ctx, tx, s.ID); err != nil {
+				return fmt.Errorf("archive season %d: %w", s.ID, err)
+			}
+			res.Archived = append(res.Archived, s)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// ensureNextSeason สร้าง season ตาม plan เมื่อไม่มี season ที่ครอบ now
+// หรือ season ล่าสุดจะจบภายใน seasonLookahead (nil = ไม่ต้องสร้าง)
+func ensureNextSeason(ctx context.Context, tx pgx.Tx, now time.Time, plan SeasonPlan) (*Season, error) {
+	var current bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM public.seasons WHERE starts_at <= $1 AND $1 < ends_at)
+	`, now).Scan(&current); err != nil {
+		return nil, err
+	}
+
+	var last *Season
+	if s, err := scanSeason(tx.QueryRow(ctx, `
+		SELECT `+seasonCols+` FROM public.seasons ORDER BY ends_at DESC LIMIT 1
+	`)); err == nil {
+		last = &s
+	} else if !errors.Is(err, ErrNoSeason) {
+		return nil, err
+	}
+	if current && last != nil && last.EndsAt.Sub(now) > seasonLookahead {
+		return nil, nil
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM public.seasons`).Scan(&count); err != nil {
+		return nil, err
+	}
+	name, start, end := plan(last, count, now)
+	s, err := scanSeason(tx.QueryRow(ctx, `
+		INSERT INTO public.seasons(name, starts_at, ends_at)
+		VALUES ($1, $2, $3)
+		RETURNING `+seasonCols, name, start, end))
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// archiveSeason เก็บคะแนนดีที่สุดต่อชื่อต่อเกมของ season ลง season_standings
+func archiveSeason(ctx context.Context, tx pgx.Tx, seasonID int64) error {
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO public.season_standings(season_id, gamename, position, rank, name, player_id, score, achieved_at)
+		SELECT $1, gamename,
+		       ROW_NUMBER() OVER (PARTITION BY gamename ORDER BY k DESC, created_at ASC),
+		       RANK()       OVER (PARTITION BY gamename ORDER BY k DESC),
+		       name, player_id, score, created_at
+		FROM (
+			SELECT DISTINCT ON (s.gamename, s.name)
+			       s.gamename, s.name, s.player_id, s.score, s.created_at,
+			       CASE WHEN g.score_order = 'asc' THEN -s.score ELSE s.score END AS k
+			FROM public.scores s
+			LEFT JOIN public.games g ON g.id = s.gamename
+			WHERE s.season_id = $1
+			ORDER BY s.gamename, s.name, k DESC, s.created_at ASC
+		) best
+		ON CONFLICT DO NOTHING
+	`, seasonID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `UPDATE public.seasons SET archived_at = now() WHERE id = $1`, seasonID)
+	return err
+}
+
+type Standing struct {
+	Position   int
+	Rank       int
+	Name       string
+	PlayerID   string
+	Score      int
+	AchievedAt time.Time
+}
+
+// GetSeasonStandings อันดับสุดท้ายของ season ที่ archive แล้ว
+// cursor ใช้ Cursor.ID เป็น position
+func GetSeasonStandings(ctx context.Context, seasonID int64, gamename string, cur *Cursor, limit int) ([]Standing, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	cond, order := "TRUE", "position ASC"
+	args := []any{seasonID, gamename, limit}
+	if cur != nil {
+		args = append(args, cur.ID)
+		cond = "position > $4"
+		if cur.Before {
+			cond, order = "position < $4", "position DESC"
+		}
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT position, rank, name, COALESCE(player_id, ''), score, achieved_at
+		FROM public.season_standings
+		WHERE season_id = $1 AND gamename = $2 AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $3
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Standing
+	for rows.Next() {
+		var s Standing
+		if err := rows.Scan(&s.Position, &s.Rank, &s.Name, &s.PlayerID, &s.Score, &s.AchievedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
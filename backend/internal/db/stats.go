@@ -0,0 +1,116 @@
+// internal/db/stats.go
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PartyResult ผลเกม party ของผู้เล่นหนึ่งคน
+type PartyResult struct {
+	PlayerID string
+	Score    int
+	Won      bool
+}
+
+// InsertPartyResults บันทึกผลทั้งห้องพร้อมกัน (เฉพาะคนที่มี player_id)
+func InsertPartyResults(ctx context.Context, roomCode string, results []PartyResult) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, r := range results {
+		batch.Queue(`
+			INSERT INTO public.party_results(room_code, player_id, score, won)
+			VALUES ($1, $2, $3, $4)
+		`, roomCode, r.PlayerID, r.Score, r.Won)
+	}
+	return pool.SendBatch(ctx, batch).Close()
+}
+
+type GameStats struct {
+	GameName   string
+	Played     int
+	Best       int // ตาม score_order ของเกม
+	Average    float64
+	LastPlayed time.Time
+}
+
+type PlayerStats struct {
+	Games            []GameStats
+	PartyPlayed      int
+	PartyWon         int
+	WordsSolved      int
+	WordsPlayed      int
+	HintsUsed        int
+	SolvedNoHints    int
+	FavoriteCategory string // "" = ยังไม่มี
+	CurrentStreak    int
+	LongestStreak    int
+}
+
+// GetPlayerStats รวมสถิติทั้งหมดของผู้เล่น (query หนัก — handler cache ไว้)
+func GetPlayerStats(ctx context.Context, playerID, tz string) (PlayerStats, error) {
+	if pool == nil {
+		return PlayerStats{}, ErrNotInitialized
+	}
+	var st PlayerStats
+
+	rows, err := pool.Query(ctx, `
+		SELECT s.gamename, count(*),
+		       CASE WHEN g.score_order = 'asc' THEN min(s.score) ELSE max(s.score) END,
+		       avg(s.score)::float8, max(s.created_at)
+		FROM public.scores s
+		LEFT JOIN public.games g ON g.id = s.gamename
+		WHERE s.player_id = $1
+		GROUP BY s.gamename, g.score_order
+		ORDER BY count(*) DESC, s.gamename
+	`, playerID)
+	if err != nil {
+		return st, err
+	}
+	for rows.Next() {
+		var g GameStats
+		if err := rows.Scan(&g.GameName, &g.Played, &g.Best, &g.Average, &g.LastPlayed); err != nil {
+			rows.Close()
+			return st, err
+		}
+		st.Games = append(st.Games, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return st, err
+	}
+
+	if err := pool.QueryRow(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE won)
+		FROM public.party_results WHERE player_id = $1
+	`, playerID).Scan(&st.PartyPlayed, &st.PartyWon); err != nil {
+		return st, err
+	}
+
+	if err := pool.QueryRow(ctx, `
+		SELECT count(*),
+		       count(*) FILTER (WHERE solved),
+		       COALESCE(sum(hints_used + ai_questions), 0),
+		       count(*) FILTER (WHERE solved AND hints_used = 0 AND ai_questions = 0),
+		       COALESCE((
+		         SELECT category FROM public.quiz_runs
+		         WHERE player_id = $1 AND category <> ''
+		         GROUP BY category
+		         ORDER BY count(*) DESC, max(created_at) DESC
+		         LIMIT 1
+		       ), '')
+		FROM public.quiz_runs WHERE player_id = $1
+	`, playerID).Scan(&st.WordsPlayed, &st.WordsSolved, &st.HintsUsed, &st.SolvedNoHints, &st.FavoriteCategory); err != nil {
+		return st, err
+	}
+
+	st.CurrentStreak, st.LongestStreak, err = SolveStreaks(ctx, playerID, tz)
+	return st, err
+}
@@ -0,0 +1,367 @@
+// internal/db/webhooks.go
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNoWebhook         = errors.New("no webhook endpoint")
+	ErrNoWebhookDelivery = errors.New("no webhook delivery")
+)
+
+// สถานะของ delivery
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // ครบจำนวนครั้งแล้วยังไม่สำเร็จ (retry เองได้จาก admin)
+)
+
+type WebhookEndpoint struct {
+	ID          int64
+	URL         string
+	Secret      string
+	Events      []string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+const webhookEndpointCols = `id, url, secret, events, description, active, created_at, updated_at`
+
+func scanWebhookEndpoint(row pgx.Row) (WebhookEndpoint, error) {
+	var e WebhookEndpoint
+	err := row.Scan(&e.ID, &e.URL, &e.Secret, &e.Events, &e.Description, &e.Active, &e.CreatedAt, &e.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return e, ErrNoWebhook
+	}
+	return e, err
+}
+
+func CreateWebhookEndpoint(ctx context.Context, e WebhookEndpoint) (WebhookEndpoint, error) {
+	if pool == nil {
+		return WebhookEndpoint{}, ErrNotInitialized
+	}
+	return scanWebhookEndpoint(pool.QueryRow(ctx, `
+		INSERT INTO public.webhook_endpoints(url, secret, events, description, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+webhookEndpointCols, e.URL, e.Secret, e.Events, e.Description, e.Active))
+}
+
+func GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error) {
+	if pool == nil {
+		return WebhookEndpoint{}, ErrNotInitialized
+	}
+	return scanWebhookEndpoint(pool.QueryRow(ctx, `SELECT `+webhookEndpointCols+` FROM public.webhook_endpoints WHERE id = $1`, id))
+}
+
+func ListWebhookEndpoints(ctx context.Context) ([]WebhookEndpoint, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `SELECT `+webhookEndpointCols+` FROM public.webhook_endpoints ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WebhookEndpoint
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// WebhookEndpointPatch: nil = ไม่เปลี่ยน
+type WebhookEndpointPatch struct {
+	URL         *string
+	Secret      *string
+	Events      *[]string
+	Description *string
+	Active      *bool
+}
+
+// UpdateWebhookEndpoint แก้เฉพาะช่องที่ไม่ใช่ nil
+// ปิด endpoint (Active=false) = งานที่ค้างในคิวของมันเป็น failed ด้วย ไม่ส่ง/retry ต่อ (admin สั่ง retry เองได้)
+func UpdateWebhookEndpoint(ctx context.Context, id int64, p WebhookEndpointPatch) (WebhookEndpoint, error) {
+	if pool == nil {
+		return WebhookEndpoint{}, ErrNotInitialized
+	}
+	var e WebhookEndpoint
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		var err error
+		e, err = scanWebhookEndpoint(tx.QueryRow(ctx, `
+			UPDATE public.webhook_endpoints SET
+				url         = COALESCE($2, url),
+				secret      = COALESCE($3, secret),
+				events      = COALESCE($4, events),
+				description = COALESCE($5, description),
+				active      = COALESCE($6, active),
+				updated_at  = now()
+			WHERE id = $1
+			RETURNING `+webhookEndpointCols, id, p.URL, p.Secret, p.Events, p.Description, p.Active))
+		if err != nil || e.Active {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE public.webhook_deliveries
+			SET status = 'failed', last_error = 'endpoint disabled', updated_at = now()
+			WHERE endpoint_id = $1 AND status = 'pending' AND event <> 'ping'
+		`, id)
+		return err
+	})
+	return e, err
+}
+
+// DeleteWebhookEndpoint ลบพร้อมคิวและ log ของมัน
+func DeleteWebhookEndpoint(ctx context.Context, id int64) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM public.webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoWebhook
+	}
+	return nil
+}
+
+// EnqueueWebhookEvent เข้าคิว payload ให้ทุก endpoint ที่เปิดอยู่และรับ event นี้ คืนจำนวนที่เข้าคิว
+// endpointID > 0 = ส่งเฉพาะ endpoint นั้น (ไม่สนว่า subscribe หรือเปิดอยู่ ใช้กับ event ทดสอบ)
+func EnqueueWebhookEvent(ctx context.Context, event string, payload []byte, playerID string, endpointID int64) (int64, error) {
+	if pool == nil {
+		return 0, ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `
+		INSERT INTO public.webhook_deliveries(endpoint_id, event, payload, player_id)
+		SELECT id, $1, $2, NULLIF($3, '')
+		FROM public.webhook_endpoints
+		WHERE CASE WHEN $4::bigint > 0 THEN id = $4
+		           ELSE active AND ($1 = ANY(events) OR '*' = ANY(events)) END
+	`, event, payload, playerID, endpointID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// WebhookJob คือ delivery ที่ถึงเวลาส่ง พร้อมข้อมูลปลายทาง
+type WebhookJob struct {
+	DeliveryID int64
+	EndpointID int64
+	URL        string
+	Secret     string
+	Event      string
+	Payload    []byte
+	Attempts   int // จำนวนครั้งที่ส่งไปแล้ว (ก่อนครั้งนี้)
+}
+
+// ClaimWebhookDeliveries จองงานที่ถึงเวลาแล้วสูงสุด limit งาน
+// เลื่อน next_attempt_at ออกไป lease ไว้ก่อน — ถ้า process ตายกลางทาง งานจะกลับมาเองหลัง lease
+// (SKIP LOCKED: รันหลาย instance พร้อมกันได้โดยไม่ส่งซ้ำ)
+// endpoint ที่ปิดอยู่ไม่ส่ง (ยกเว้น ping ที่ admin สั่งเอง) — กันงานที่เข้าคิวพร้อมกับตอนปิด
+func ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookJob, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		WITH due AS (
+			SELECT d.id FROM public.webhook_deliveries d
+			JOIN public.webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= now()
+			  AND (e.active OR d.event = 'ping')
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE public.webhook_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $2), updated_at = now()
+		FROM due, public.webhook_endpoints e
+		WHERE d.id = due.id AND e.id = d.endpoint_id
+		RETURNING d.id, e.id, e.url, e.secret, d.event, d.payload::text, d.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WebhookJob
+	for rows.Next() {
+		var j WebhookJob
+		var payload string
+		if err := rows.Scan(&j.DeliveryID, &j.EndpointID, &j.URL, &j.Secret, &j.Event, &payload, &j.Attempts); err != nil {
+			return nil, err
+		}
+		j.Payload = []byte(payload)
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+// WebhookAttempt คือผลการส่งหนึ่งครั้ง (delivery log)
+type WebhookAttempt struct {
+	ID         int64
+	DeliveryID int64
+	Attempt    int
+	StatusCode int // 0 = ต่อไม่ติด/timeout
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// RecordWebhookAttempt บันทึกผลส่งครั้งนี้และสถานะต่อไปของ delivery
+// status = pending ต้องมี next (เวลาลองใหม่)
+func RecordWebhookAttempt(ctx context.Context, a WebhookAttempt, status string, next time.Time) error {
+	if pool == nil {
+		return ErrNotInitialized
+	}
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO public.webhook_attempts(delivery_id, attempt, status_code, error, duration_ms)
+			VALUES ($1, $2, $3, $4, $5)
+		`, a.DeliveryID, a.Attempt, a.StatusCode, a.Error, a.Duration.Milliseconds()); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE public.webhook_deliveries
+			SET status = $2, attempts = $3, last_status = $4, last_error = $5,
+			    next_attempt_at = COALESCE($6, next_attempt_at), updated_at = now()
+			WHERE id = $1
+		`, a.DeliveryID, status, a.Attempt, a.StatusCode, a.Error, nullTime(next))
+		return err
+	})
+}
+
+type WebhookDelivery struct {
+	ID            int64
+	EndpointID    int64
+	Event         string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastStatus    int
+	LastError     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+const webhookDeliveryCols = `id, endpoint_id, event, payload::text, status, attempts, next_attempt_at,
+	last_status, last_error, created_at, updated_at`
+
+func scanWebhookDelivery(row pgx.Row) (WebhookDelivery, error) {
+	var d WebhookDelivery
+	var payload string
+	err := row.Scan(&d.ID, &d.EndpointID, &d.Event, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatus, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return d, ErrNoWebhookDelivery
+	}
+	d.Payload = []byte(payload)
+	return d, err
+}
+
+// ListWebhookDeliveries ใหม่สุดก่อน ต่อจาก cur (status ว่าง = ทุกสถานะ)
+func ListWebhookDeliveries(ctx context.Context, endpointID int64, status string, cur *Cursor, limit int) ([]WebhookDelivery, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	cond, order, cargs := createdKeyset(cur, 3)
+	rows, err := pool.Query(ctx, `
+		SELECT `+webhookDeliveryCols+`
+		FROM public.webhook_deliveries
+		WHERE endpoint_id = $1 AND ($2 = '' OR status = $2) AND `+cond+`
+		ORDER BY `+order+`
+		LIMIT $3
+	`, append([]any{endpointID, status, limit}, cargs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.Before {
+		reverse(out)
+	}
+	return out, nil
+}
+
+func GetWebhookDelivery(ctx context.Context, endpointID, id int64) (WebhookDelivery, error) {
+	if pool == nil {
+		return WebhookDelivery{}, ErrNotInitialized
+	}
+	return scanWebhookDelivery(pool.QueryRow(ctx, `
+		SELECT `+webhookDeliveryCols+` FROM public.webhook_deliveries WHERE id = $1 AND endpoint_id = $2
+	`, id, endpointID))
+}
+
+// ListWebhookAttempts: log การส่งของ delivery เรียงตามครั้ง
+func ListWebhookAttempts(ctx context.Context, deliveryID int64) ([]WebhookAttempt, error) {
+	if pool == nil {
+		return nil, ErrNotInitialized
+	}
+	rows, err := pool.Query(ctx, `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM public.webhook_attempts WHERE delivery_id = $1 ORDER BY attempt, id
+	`, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WebhookAttempt
+	for rows.Next() {
+		var a WebhookAttempt
+		var ms int64
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &ms, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Duration = time.Duration(ms) * time.Millisecond
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// RetryWebhookDelivery ส่งใหม่ทันที (ส่งสำเร็จไปแล้วก็ส่งซ้ำได้) — นับครั้งต่อจากเดิม
+func RetryWebhookDelivery(ctx context.Context, endpointID, id int64) (WebhookDelivery, error) {
+	if pool == nil {
+		return WebhookDelivery{}, ErrNotInitialized
+	}
+	return scanWebhookDelivery(pool.QueryRow(ctx, `
+		UPDATE public.webhook_deliveries
+		SET status = 'pending', next_attempt_at = now(), updated_at = now()
+		WHERE id = $1 AND endpoint_id = $2
+		RETURNING `+webhookDeliveryCols, id, endpointID))
+}
+
+// PruneWebhookDeliveries ลบ delivery ที่จบแล้ว (ส่งได้/ล้มเหลว) ที่เก่ากว่า before
+func PruneWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	if pool == nil {
+		return 0, ErrNotInitialized
+	}
+	tag, err := pool.Exec(ctx, `
+		DELETE FROM public.webhook_deliveries
+		WHERE status <> 'pending' AND updated_at < $1
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
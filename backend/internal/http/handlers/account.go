@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/moderation"
+)
+
+var usernameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+const (
+	minPasswordLen = 8
+	maxPasswordLen = 128
+)
+
+type tokenResp struct {
+	Player           playerResp `json:"player"`
+	TokenType        string     `json:"token_type"`
+	AccessToken      string     `json:"access_token"`
+	AccessExpiresAt  string     `json:"access_expires_at"`
+	RefreshToken     string     `json:"refresh_token"`
+	RefreshExpiresAt string     `json:"refresh_expires_at"`
+}
+
+// startSession สร้าง session ใหม่ให้ player แล้วออก access + refresh token
+func startSession(r *http.Request, p db.Player) (tokenResp, error) {
+	sid, err := auth.NewID()
+	if err != nil {
+		return tokenResp{}, err
+	}
+	refresh, hash, err := auth.NewRefreshToken(sid)
+	if err != nil {
+		return tokenResp{}, err
+	}
+	now := time.Now()
+	refreshExp := now.Add(auth.RefreshTTL())
+	s := db.Session{ID: sid, PlayerID: p.ID, RefreshHash: hash, ExpiresAt: refreshExp}
+	if err := db.CreateSession(r.Context(), s, truncate(r.UserAgent(), 256)); err != nil {
+		return tokenResp{}, err
+	}
+	access, accessExp := auth.IssueAccessToken(p.ID, sid, now)
+	return tokenResp{
+		Player:           toPlayerResp(p),
+		TokenType:        "Bearer",
+		AccessToken:      access,
+		AccessExpiresAt:  accessExp.Format(timeLayout),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExp.Format(timeLayout),
+	}, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// POST /api/auth/register {username, password, displayName?}
+// ถ้าส่ง X-Player-Token ของ guest มาด้วย จะอัปเกรด guest คนนั้นเป็นบัญชี (id/ประวัติเดิม)
+func Register(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Username    string `json:"username"`
+		Password    string `json:"password"`
+		DisplayName string `json:"displayName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	in.Username = strings.TrimSpace(in.Username)
+	in.DisplayName = strings.TrimSpace(in.DisplayName)
+
+	guest, err := requestPlayer(r)
+	if errors.Is(err, errBadPlayerToken) {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Register: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot verify player")
+		return
+	}
+	if guest != nil && guest.Kind != "guest" {
+		writeError(w, http.StatusConflict, "already registered")
+		return
+	}
+	if in.DisplayName == "" {
+		in.DisplayName = in.Username
+		if guest != nil {
+			in.DisplayName = guest.DisplayName
+		}
+	}
+	if !usernameRe.MatchString(in.Username) {
+		writeError(w, http.StatusBadRequest, "username must be 3-32 letters, digits, '.', '_' or '-'")
+		return
+	}
+	if n := utf8.RuneCountInString(in.Password); n < minPasswordLen || n > maxPasswordLen {
+		writeError(w, http.StatusBadRequest, "password must be 8-128 characters")
+		return
+	}
+	if utf8.RuneCountInString(in.DisplayName) > maxDisplayName {
+		writeError(w, http.StatusBadRequest, "name too long (max 20 characters)")
+		return
+	}
+	// username ใช้ login ตลอดไป mask ไม่ได้ — เจอคำหยาบก็ปฏิเสธเลย
+	if d := moderation.Review(moderation.FieldName, in.Username); d.Action != moderation.ActionAllow {
+		writeError(w, http.StatusUnprocessableEntity, "username contains inappropriate language")
+		return
+	}
+	var ok bool
+	if in.DisplayName, ok = moderate(w, r, moderation.FieldName, in.DisplayName); !ok {
+		return
+	}
+
+	hash, err := auth.HashPassword(in.Password)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot create account")
+		return
+	}
+	var p db.Player
+	if guest != nil {
+		p, err = db.UpgradeGuest(r.Context(), guest.ID, in.Username, in.DisplayName, hash)
+	} else {
+		var id string
+		if id, err = auth.NewID(); err != nil {
+			writeError(w, http.StatusInternalServerError, "cannot generate id")
+			return
+		}
+		p, err = db.CreateAccount(r.Context(), id, in.Username, in.DisplayName, hash)
+	}
+	switch {
+	case errors.Is(err, db.ErrNotGuest):
+		writeError(w, http.StatusConflict, "already registered")
+		return
+	case errors.Is(err, db.ErrUsernameTaken):
+		writeError(w, http.StatusConflict, "username already taken")
+		return
+	case errors.Is(err, db.ErrNameTaken):
+		writeError(w, http.StatusConflict, "name already taken")
+		return
+	case err != nil:
+		log.Printf("Register: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot create account")
+		return
+	}
+
+	out, err := startSession(r, p)
+	if err != nil {
+		log.Printf("Register: session: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot start session")
+		return
+	}
+	writeJSON(w, http.StatusCreated, out)
+}
+
+// POST /api/auth/login {username, password}
+func Login(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	in.Username = strings.TrimSpace(in.Username)
+
+	acc, err := db.GetAccountByUsername(r.Context(), in.Username)
+	if errors.Is(err, db.ErrNoAccount) {
+		auth.CheckDummyPassword(in.Password) // กันเดา username จากเวลาที่ตอบ
+		writeError(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+	if err != nil {
+		log.Printf("Login: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot login")
+		return
+	}
+	ok, err := auth.CheckPassword(acc.PasswordHash, in.Password)
+	if err != nil {
+		log.Printf("Login: %s: %v", acc.PlayerID, err)
+	}
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+
+	out, err := startSession(r, acc.Player)
+	if err != nil {
+		log.Printf("Login: session: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot start session")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /api/auth/refresh {refreshToken}
+// หมุน refresh token ทุกครั้ง ถ้ามีคนเอา token เก่ามาใช้ซ้ำ ถือว่าหลุด → ยกเลิก session ทิ้ง
+func RefreshSession(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	sid, sec, err := auth.ParseRefreshToken(strings.TrimSpace(in.RefreshToken))
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+
+	s, err := db.GetSession(r.Context(), sid)
+	if errors.Is(err, db.ErrNoSession) {
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	if err != nil {
+		log.Printf("RefreshSession: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot refresh session")
+		return
+	}
+	oldHash := auth.HashRefreshSecret(sec)
+	if s.RevokedAt != nil || time.Now().After(s.ExpiresAt) {
+		writeError(w, http.StatusUnauthorized, "session expired")
+		return
+	}
+	if oldHash != s.RefreshHash {
+		log.Printf("RefreshSession: refresh token reuse on session %s, revoking", sid)
+		revokeSession(r, sid)
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+
+	p, err := db.GetPlayer(r.Context(), s.PlayerID)
+	if err != nil {
+		log.Printf("RefreshSession: %v", err)
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	refresh, newHash, err := auth.NewRefreshToken(sid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot refresh session")
+		return
+	}
+	now := time.Now()
+	refreshExp := now.Add(auth.RefreshTTL())
+	if err := db.RotateSession(r.Context(), sid, oldHash, newHash, refreshExp); err != nil {
+		if errors.Is(err, db.ErrNoSession) {
+			writeError(w, http.StatusUnauthorized, "invalid refresh token")
+			return
+		}
+		log.Printf("RefreshSession: rotate: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot refresh session")
+		return
+	}
+
+	access, accessExp := auth.IssueAccessToken(p.ID, sid, now)
+	writeJSON(w, http.StatusOK, tokenResp{
+		Player:           toPlayerResp(p),
+		TokenType:        "Bearer",
+		AccessToken:      access,
+		AccessExpiresAt:  accessExp.Format(timeLayout),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExp.Format(timeLayout),
+	})
+}
+
+// POST /api/auth/logout {refreshToken?, all?} (Authorization: Bearer)
+// all=true ออกจากระบบทุกเครื่องของบัญชีนี้
+func Logout(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		RefreshToken string `json:"refreshToken"`
+		All          bool   `json:"all"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+	}
+
+	id, _ := auth.FromContext(r.Context())
+	sid := id.SessionID
+	if sid == "" && in.RefreshToken != "" {
+		// access token หมดอายุไปแล้วก็ยัง logout ด้วย refresh token ได้
+		parsed, sec, err := auth.ParseRefreshToken(strings.TrimSpace(in.RefreshToken))
+		if err == nil {
+			if s, err := db.GetSession(r.Context(), parsed); err == nil && s.RefreshHash == auth.HashRefreshSecret(sec) {
+				sid, id.PlayerID = s.ID, s.PlayerID
+			}
+		}
+	}
+	if sid == "" {
+		writeError(w, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	if in.All {
+		ids, err := db.RevokePlayerSessions(r.Context(), id.PlayerID)
+		if err != nil {
+			log.Printf("Logout: %v", err)
+			writeError(w, http.StatusInternalServerError, "cannot logout")
+			return
+		}
+		for _, s := range ids {
+			auth.RevokeSession(s)
+		}
+	} else if err := revokeSession(r, sid); err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot logout")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+// revokeSession ยกเลิกใน DB (refresh ใช้ไม่ได้) และในหน่วยความจำ (access token ที่ออกไปแล้ว)
+func revokeSession(r *http.Request, sid string) error {
+	if err := db.RevokeSession(r.Context(), sid); err != nil {
+		log.Printf("revoke session %s: %v", sid, err)
+		return err
+	}
+	auth.RevokeSession(sid)
+	return nil
+}
+
+// POST /api/players/merge {guestToken, keepName: "account"|"guest"} (Authorization: Bearer)
+// รวมคะแนน/ประวัติของ guest (จากเครื่องอื่น) เข้ากับบัญชีที่ login อยู่ แล้วลบ guest ทิ้ง
+func MergeGuest(w http.ResponseWriter, r *http.Request) {
+	id, ok := auth.FromContext(r.Context())
+	if !ok || !id.Registered() {
+		writeError(w, http.StatusUnauthorized, "login required")
+		return
+	}
+	var in struct {
+		GuestToken string `json:"guestToken"`
+		KeepName   string `json:"keepName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if in.KeepName != "" && in.KeepName != "account" && in.KeepName != "guest" {
+		writeError(w, http.StatusBadRequest, "keepName must be 'account' or 'guest'")
+		return
+	}
+	guestID, err := auth.VerifyDeviceToken(strings.TrimSpace(in.GuestToken))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid guest token")
+		return
+	}
+	if guestID == id.PlayerID {
+		writeError(w, http.StatusBadRequest, "cannot merge a player into itself")
+		return
+	}
+
+	res, err := db.MergeGuest(r.Context(), guestID, id.PlayerID, in.KeepName == "guest")
+	switch {
+	case errors.Is(err, db.ErrNoPlayer):
+		writeError(w, http.StatusNotFound, "guest not found")
+		return
+	case errors.Is(err, db.ErrNotGuest):
+		writeError(w, http.StatusConflict, "only guest players can be merged")
+		return
+	case err != nil:
+		log.Printf("MergeGuest: %s -> %s: %v", guestID, id.PlayerID, err)
+		writeError(w, http.StatusInternalServerError, "cannot merge player")
+		return
+	}
+
+	rebindSeats(guestID, res.Account.ID, res.Account.DisplayName)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"player":             toPlayerResp(res.Account),
+		"guest_name":         res.GuestName,
+		"scores_moved":       res.ScoresMoved,
+		"duplicates_removed": res.DuplicatesRemoved,
+		"quiz_runs_moved":    res.QuizRunsMoved,
+	})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/achievements"
+	"my-app-backend/internal/db"
+)
+
+func init() {
+	achievements.SetNotifier(notifyRoomBadge)
+}
+
+// emitAchievement ตรวจ badge เบื้องหลัง ไม่ให้ request (หรือ lock ของห้อง) ต้องรอ
+func emitAchievement(ev achievements.Event) {
+	if ev.PlayerID == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := achievements.Emit(ctx, ev); err != nil {
+			log.Printf("achievements %s: %v", ev.Kind, err)
+		}
+	}()
+}
+
+// notifyRoomBadge แจ้ง "achievement_unlocked" ในห้อง party ที่ผู้เล่นอยู่ (ถ้ามี)
+func notifyRoomBadge(ev achievements.Event, b achievements.Badge) {
+	code, name := ev.RoomCode, ev.Name
+	if code == "" {
+		rooms.Range(func(_, value any) bool {
+			st := value.(*roomState)
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			for _, p := range st.players {
+				if p.PlayerID == ev.PlayerID {
+					code, name = st.room.Code, p.Name
+					return false
+				}
+			}
+			return true
+		})
+	}
+	if code == "" {
+		return
+	}
+	wsHubBroadcast(code, hubMsg{Type: "achievement_unlocked", Name: name, Badge: &b})
+}
+
+// GET /api/achievements — badge ทั้งหมดที่ปลดล็อกได้
+func ListAchievements(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"items": achievements.Catalog()})
+}
+
+type playerBadgeResp struct {
+	achievements.Badge
+	UnlockedAt string `json:"unlocked_at"`
+}
+
+// GET /api/players/{id}/badges ("me" = ผู้เล่นของ token)
+func GetPlayerBadges(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "me" {
+		if id = requestPlayerID(r); id == "" {
+			writeError(w, http.StatusUnauthorized, "player token required")
+			return
+		}
+	}
+	if _, err := db.LookupPlayer(r.Context(), id); errors.Is(err, db.ErrNoPlayer) {
+		writeError(w, http.StatusNotFound, "player not found")
+		return
+	} else if err != nil {
+		log.Printf("GetPlayerBadges: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load player")
+		return
+	}
+
+	rows, err := db.ListPlayerBadges(r.Context(), id)
+	if err != nil {
+		log.Printf("GetPlayerBadges: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load badges")
+		return
+	}
+	out := make([]playerBadgeResp, 0, len(rows))
+	for _, row := range rows {
+		b, ok := achievements.Lookup(row.BadgeID)
+		if !ok {
+			continue // badge ที่เลิกใช้แล้ว
+		}
+		out = append(out, playerBadgeResp{Badge: b, UnlockedAt: row.UnlockedAt.Format(timeLayout)})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"player_id": id, "items": out})
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+)
+
+const (
+	maxFeedbackTags = 10
+	maxTagLen       = 32
+	maxNoteLen      = 4000
+)
+
+type adminFeedbackResp struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Contact   string   `json:"contact"`
+	Message   string   `json:"message"`
+	Source    string   `json:"source"`
+	PlayerID  string   `json:"player_id,omitempty"`
+	Status    string   `json:"status"`
+	Tags      []string `json:"tags"`
+	Published bool     `json:"published"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+
+	Context     db.FeedbackContext `json:"context"`
+	Attachments []attachmentResp   `json:"attachments"`
+}
+
+// toAdminFeedbackResp: as = รูปแนบของ f (nil = ไม่มี)
+func toAdminFeedbackResp(f db.AdminFeedback, as []db.Attachment) adminFeedbackResp {
+	tags := f.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return adminFeedbackResp{
+		ID:        f.ID,
+		Name:      f.Name,
+		Contact:   f.Contact,
+		Message:   f.Message,
+		Source:    f.Source,
+		PlayerID:  f.PlayerID,
+		Status:    f.Status,
+		Tags:      tags,
+		Published: f.Published,
+		CreatedAt: f.CreatedAt.Format(timeLayout),
+		UpdatedAt: f.UpdatedAt.Format(timeLayout),
+
+		Context:     f.Context,
+		Attachments: toAttachmentResps(as),
+	}
+}
+
+type feedbackNoteResp struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+func toFeedbackNoteResp(n db.FeedbackNote) feedbackNoteResp {
+	return feedbackNoteResp{ID: n.ID, Kind: n.Kind, Author: n.Author, Body: n.Body, CreatedAt: n.CreatedAt.Format(timeLayout)}
+}
+
+// parseDateParam รับ "2006-01-02" (วันตามเวลาไทย) หรือ RFC3339
+// endOfDay=true กับแบบวันที่ = ต้นวันถัดไป (ให้ ?to= รวมทั้งวัน)
+func parseDateParam(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", v, bangkok)
+	if err != nil {
+		return time.Time{}, errors.New("invalid date (use YYYY-MM-DD or RFC3339)")
+	}
+	if endOfDay {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d, nil
+}
+
+// normalizeTags: ตัวเล็ก ตัดช่องว่าง ไม่ซ้ำ
+func normalizeTags(in []string) ([]string, error) {
+	out := make([]string, 0, len(in))
+	for _, t := range in {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || slices.Contains(out, t) {
+			continue
+		}
+		if utf8.RuneCountInString(t) > maxTagLen {
+			return nil, errors.New("tag too long (max 32 characters)")
+		}
+		out = append(out, t)
+	}
+	if len(out) > maxFeedbackTags {
+		return nil, errors.New("too many tags (max 10)")
+	}
+	return out, nil
+}
+
+func feedbackIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid feedback id")
+		return 0, false
+	}
+	return id, true
+}
+
+// adminFeedbackFilter อ่าน filter ที่ใช้ร่วมกันระหว่างรายการกับการค้นหา
+// ok=false แปลว่าเขียน response ไปแล้ว
+func adminFeedbackFilter(w http.ResponseWriter, r *http.Request) (db.FeedbackFilter, bool) {
+	q := r.URL.Query()
+	f := db.FeedbackFilter{
+		Status: strings.TrimSpace(q.Get("status")),
+		Source: strings.TrimSpace(q.Get("source")),
+		Tag:    strings.ToLower(strings.TrimSpace(q.Get("tag"))),
+
+		RequestID: strings.TrimSpace(q.Get("request_id")),
+		RoomCode:  strings.ToUpper(strings.TrimSpace(q.Get("room"))),
+	}
+	if f.Status != "" && !slices.Contains(db.FeedbackStatuses, f.Status) {
+		writeError(w, http.StatusBadRequest, "invalid status")
+		return f, false
+	}
+	var err error
+	if f.Range.From, err = parseDateParam(q.Get("from"), false); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return f, false
+	}
+	if f.Range.To, err = parseDateParam(q.Get("to"), true); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return f, false
+	}
+	return f, true
+}
+
+// GET /api/admin/feedback?status=new&source=FeedbackPage&tag=bug&from=2026-01-01&to=2026-01-31[&limit=20][&cursor=...]
+// ตามรอยจาก log/ห้อง: &request_id=host/abc-000123&room=ABC123
+func AdminListFeedback(w http.ResponseWriter, r *http.Request) {
+	limit, cur, err := pageParams(r, 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f, ok := adminFeedbackFilter(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.ListAdminFeedback(r.Context(), f, cur, limit+1)
+	if err != nil {
+		log.Printf("AdminListFeedback: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load feedback")
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(f db.AdminFeedback) db.Cursor {
+		return db.Cursor{CreatedAt: f.CreatedAt, ID: f.ID}
+	})
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	atts := feedbackAttachments(r, ids...)
+	out := make([]adminFeedbackResp, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAdminFeedbackResp(row, atts[row.ID]))
+	}
+	writeJSON(w, http.StatusOK, pageResp[adminFeedbackResp]{Items: out, NextCursor: next, PrevCursor: prev})
+}
+
+// GET /api/admin/feedback/{id} — รายการเต็มพร้อม note
+func AdminGetFeedback(w http.ResponseWriter, r *http.Request) {
+	id, ok := feedbackIDParam(w, r)
+	if !ok {
+		return
+	}
+	f, err := db.GetAdminFeedback(r.Context(), id)
+	if errors.Is(err, db.ErrNoFeedback) {
+		writeError(w, http.StatusNotFound, "feedback not found")
+		return
+	}
+	if err != nil {
+		log.Printf("AdminGetFeedback: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load feedback")
+		return
+	}
+	notes, err := db.ListFeedbackNotes(r.Context(), id)
+	if err != nil {
+		log.Printf("AdminGetFeedback: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load notes")
+		return
+	}
+	outNotes := make([]feedbackNoteResp, 0, len(notes))
+	for _, n := range notes {
+		outNotes = append(outNotes, toFeedbackNoteResp(n))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"feedback": toAdminFeedbackResp(f, feedbackAttachments(r, id)[id]),
+		"notes":    outNotes,
+	})
+}
+
+// PATCH /api/admin/feedback/{id} {status?, tags?, published?}
+// status: new → triaged → resolved, ทุกสถานะไป spam ได้ (resolved เปิดใหม่เป็น triaged, spam กลับเป็น new)
+func AdminUpdateFeedback(w http.ResponseWriter, r *http.Request) {
+	id, ok := feedbackIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Status    *string   `json:"status"`
+		Tags      *[]string `json:"tags"`
+		Published *bool     `json:"published"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	patch := db.FeedbackPatch{Status: in.Status, Published: in.Published}
+	if in.Tags != nil {
+		tags, err := normalizeTags(*in.Tags)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		patch.Tags = &tags
+	}
+
+	admin, _ := auth.AdminFromContext(r.Context())
+	f, err := db.UpdateFeedback(r.Context(), id, patch, admin)
+	switch {
+	case errors.Is(err, db.ErrNoFeedback):
+		writeError(w, http.StatusNotFound, "feedback not found")
+		return
+	case errors.Is(err, db.ErrUnknownStatus):
+		writeError(w, http.StatusBadRequest, "invalid status")
+		return
+	case errors.Is(err, db.ErrBadTransition):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("AdminUpdateFeedback: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot update feedback")
+		return
+	}
+	writeJSON(w, http.StatusOK, toAdminFeedbackResp(f, feedbackAttachments(r, id)[id]))
+}
+
+// POST /api/admin/feedback/{id}/notes {body} — note ภายใน (ไม่แสดงต่อสาธารณะ)
+func AdminAddFeedbackNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := feedbackIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	in.Body = strings.TrimSpace(in.Body)
+	if in.Body == "" || utf8.RuneCountInString(in.Body) > maxNoteLen {
+		writeError(w, http.StatusBadRequest, "body must be 1-4000 characters")
+		return
+	}
+	admin, _ := auth.AdminFromContext(r.Context())
+	n, err := db.AddFeedbackNote(r.Context(), id, admin, in.Body)
+	if errors.Is(err, db.ErrNoFeedback) {
+		writeError(w, http.StatusNotFound, "feedback not found")
+		return
+	}
+	if err != nil {
+		log.Printf("AdminAddFeedbackNote: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot add note")
+		return
+	}
+	writeJSON(w, http.StatusCreated, toFeedbackNoteResp(n))
+}
@@ -1,132 +1,113 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-app-backend/internal/llm"
 )
 
+// ข้อความยาวสุดที่รับต่อครั้ง (ตัวอักษร)
+const maxChatRunes = 2000
+
 type ChatRequest struct {
 	Message string `json:"message"`
+
+	// บทสนทนา (ต้องมี player token): 0 = เริ่มใหม่ ดู chat_conversations.go
+	ConversationID int64  `json:"conversation_id,omitempty"`
+	UseCase        string `json:"use_case,omitempty"` // ใช้ตอนเริ่มใหม่ (default general)
 }
 
 type OpenAIRespLite struct {
-	OutputText string `json:"output_text"`
+	OutputText     string `json:"output_text"`
+	Provider       string `json:"provider,omitempty"`
+	Model          string `json:"model,omitempty"`
+	ConversationID int64  `json:"conversation_id,omitempty"` // 0 = ไม่ได้บันทึก (ไม่มี player token)
 }
 
-// --- ฟังก์ชันช่วย: ดึงข้อความจาก response รูปแบบต่างๆ ของ Responses API ---
-func extractOutputText(raw map[string]any) string {
-	// 1) ทางลัด: ถ้ามี output_text ใช้เลย
-	if v, ok := raw["output_text"].(string); ok && v != "" {
-		return v
-	}
-	// 2) รูปแบบหลักของ Responses API: output -> [ message ] -> content -> [ {type: output_text, text: "..."} ]
-	if out, ok := raw["output"].([]any); ok {
-		for _, item := range out {
-			msg, _ := item.(map[string]any)
-			if msg == nil {
-				continue
-			}
-			if msg["type"] == "message" {
-				if content, ok2 := msg["content"].([]any); ok2 {
-					for _, c := range content {
-						part, _ := c.(map[string]any)
-						if part == nil {
-							continue
-						}
-						// มาตรฐานใหม่จะเป็น type: "output_text"
-						if (part["type"] == "output_text" || part["type"] == "text") && part["text"] != nil {
-							if s, ok3 := part["text"].(string); ok3 && s != "" {
-								return s
-							}
-						}
-					}
-				}
-			}
-		}
+// POST /api/chat {message, conversation_id?, use_case?} → {output_text, conversation_id}
+// provider เลือกจาก LLM_PROVIDER (mock | openai | local, ดู internal/llm) โควตา/งบดู chat_budget.go
+// แบบทยอยส่งดู ChatStreamHandler (chat_stream.go)
+func ChatHandler(w http.ResponseWriter, r *http.Request) {
+	t, ok := prepareChat(w, r)
+	if !ok {
+		return
 	}
-	// 3) กันเหนียว: ถ้าบางรุ่นส่งเป็น choices แบบเดิม (ไม่ค่อยเกิดใน /v1/responses)
-	if choices, ok := raw["choices"].([]any); ok && len(choices) > 0 {
-		if ch0, ok := choices[0].(map[string]any); ok {
-			if txt, ok := ch0["text"].(string); ok && txt != "" {
-				return txt
-			}
-			// รูปแบบ chat-completions เก่า
-			if msg, ok := ch0["message"].(map[string]any); ok {
-				if content, ok := msg["content"].(string); ok && content != "" {
-					return content
-				}
-			}
-		}
+
+	ctx, cancel := context.WithTimeout(r.Context(), llm.Timeout())
+	defer cancel()
+	resp, err := chatProvider(w, r).Complete(ctx, t.req)
+	if err != nil {
+		writeLLMError(w, "chat", err)
+		return
 	}
-	return ""
+	recordLLMUsage(t.useCase, t.req, resp)
+	writeJSON(w, http.StatusOK, OpenAIRespLite{
+		OutputText:     resp.Text,
+		Provider:       resp.Provider,
+		Model:          resp.Model,
+		ConversationID: saveChatTurn(r.Context(), t, resp),
+	})
 }
 
-// ยิง api หา chatGPT จริงต้องเสียตังเลยปิดไว้ก่อน
-// func ChatHandler(w http.ResponseWriter, r *http.Request) {
-// 	var req ChatRequest
-// 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-// 		http.Error(w, "Bad request", http.StatusBadRequest)
-// 		return
-// 	}
-
-// 	apiKey := os.Getenv("OPENAI_API_KEY")
-// 	if apiKey == "" {
-// 		http.Error(w, "Server misconfigured: OPENAI_API_KEY not set", http.StatusInternalServerError)
-// 		return
-// 	}
-
-// 	payload := map[string]any{
-// 		"model": "gpt-4.1-mini",
-// 		"input": "ตอบสั้น กระชับ: " + req.Message,
-// 	}
-// 	body, _ := json.Marshal(payload)
-
-// 	httpReq, _ := http.NewRequest("POST", "https://api.openai.com/v1/responses", bytes.NewBuffer(body))
-// 	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
-// 	httpReq.Header.Set("Content-Type", "application/json")
-
-// 	resp, err := http.DefaultClient.Do(httpReq)
-// 	if err != nil {
-// 		http.Error(w, "OpenAI error", http.StatusBadGateway)
-// 		return
-// 	}
-// 	defer resp.Body.Close()
-
-// 	respBody, _ := io.ReadAll(resp.Body)
-
-// 	// passthrough error จาก OpenAI (จะเห็นข้อความจริง)
-// 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-// 		log.Printf("OpenAI %d: %s", resp.StatusCode, string(respBody))
-// 		w.WriteHeader(resp.StatusCode)
-// 		w.Header().Set("Content-Type", "application/json")
-// 		w.Write(respBody)
-// 		return
-// 	}
-
-// 	var raw map[string]any
-// 	if err := json.Unmarshal(respBody, &raw); err != nil {
-// 		http.Error(w, "Decode error", http.StatusInternalServerError)
-// 		return
-// 	}
-
-// 	out := OpenAIRespLite{OutputText: extractOutputText(raw)}
-// 	w.Header().Set("Content-Type", "application/json")
-// 	json.NewEncoder(w).Encode(out)
-// }
-
-// ใช้ mock response แทนเรียก OpenAI
-func ChatHandler(w http.ResponseWriter, r *http.Request) {
+// decodeChat อ่านและตรวจข้อความ ok=false แปลว่าเขียน response ไปแล้ว
+func decodeChat(w http.ResponseWriter, r *http.Request) (ChatRequest, bool) {
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
+		return req, false
 	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		writeError(w, http.StatusBadRequest, "message required")
+		return req, false
+	}
+	if utf8.RuneCountInString(req.Message) > maxChatRunes {
+		writeError(w, http.StatusRequestEntityTooLarge, "message too long (max 2000 characters)")
+		return req, false
+	}
+	return req, true
+}
 
-	// mock response สำหรับ dev (ไม่เสียตังค์)
-	out := OpenAIRespLite{
-		OutputText: "นี่คือข้อความ mock จาก backend: " + req.Message,
+// writeLLMError แปลง error ของ provider เป็น status ที่ client เข้าใจ (รายละเอียดจริงอยู่ใน log)
+func writeLLMError(w http.ResponseWriter, where string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return // client ปิดไปแล้ว
+	}
+	log.Printf("%s: %v", where, err)
+	code, msg, retryAfter := llmErrorStatus(err)
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
 	}
+	writeError(w, code, msg)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(out)
+// llmErrorStatus: status/ข้อความที่ส่งให้ client ตามชนิด error (ใช้ร่วมกับ SSE)
+func llmErrorStatus(err error) (int, string, time.Duration) {
+	switch {
+	case errors.Is(err, llm.ErrTimeout):
+		return http.StatusGatewayTimeout, "chat provider timed out", 0
+	case errors.Is(err, llm.ErrRateLimited):
+		var apiErr *llm.APIError
+		var retryAfter time.Duration
+		if errors.As(err, &apiErr) {
+			retryAfter = apiErr.RetryAfter
+		}
+		return http.StatusTooManyRequests, "chat provider is busy, try again later", retryAfter
+	case errors.Is(err, llm.ErrRejected):
+		return http.StatusUnprocessableEntity, "message rejected by chat provider", 0
+	case errors.Is(err, llm.ErrMisconfigured):
+		return http.StatusServiceUnavailable, "chat is not available", 0
+	case errors.Is(err, llm.ErrUnavailable):
+		return http.StatusServiceUnavailable, "chat provider unavailable", 0
+	default:
+		return http.StatusBadGateway, "bad response from chat provider", 0
+	}
 }
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/llm"
+	"my-app-backend/internal/ratelimit"
+)
+
+// โควตาและงบของ LLM (/api/chat, /api/chat/stream, /api/quiz/ask ใช้ร่วมกัน) อ่าน env ตอนใช้
+//
+//	CHAT_RATE_IP               ต่อ IP        default 20/10m
+//	CHAT_RATE_PLAYER           ต่อ player id default 60/1h
+//	CHAT_RATE_GLOBAL           ทั้งเซิร์ฟเวอร์ เฉพาะตอนใช้ provider จริง default 300/1h
+//	LLM_DAILY_TOKEN_BUDGET     token (เข้า+ออก) ต่อวันของ provider จริง (0 = ไม่จำกัด)
+//	LLM_DAILY_COST_BUDGET_USD  ค่าใช้จ่ายต่อวัน (0 = ไม่จำกัด)
+//	LLM_PRICE_INPUT_PER_1M, LLM_PRICE_OUTPUT_PER_1M  ราคา USD ต่อ 1M token (ใช้คิดค่าใช้จ่าย)
+//
+// วันนับตามเวลาไทย งบหมดแล้วตอบด้วย mock แทน (ไม่ปิดแชท) พร้อม header X-LLM-Degraded: budget
+//
+// โควตาต่อ IP ใช้ clientIP ซึ่ง appmw.RealIP เชื่อ X-Forwarded-For เฉพาะจาก TRUSTED_PROXIES
+// ส่วนโควตารวมกันกรณีมีคนวน IP จริงหลายเครื่องจนงบวันนั้นหมดเร็ว
+var (
+	chatLimitOnce   sync.Once
+	chatIPLimit     *ratelimit.Limiter
+	chatPlayerLimit *ratelimit.Limiter
+	chatGlobalLimit *ratelimit.Limiter
+)
+
+func chatLimiters() (ip, player, global *ratelimit.Limiter) {
+	chatLimitOnce.Do(func() {
+		chatIPLimit = ratelimit.Parse(os.Getenv("CHAT_RATE_IP"), ratelimit.New(20, 10*time.Minute))
+		chatPlayerLimit = ratelimit.Parse(os.Getenv("CHAT_RATE_PLAYER"), ratelimit.New(60, time.Hour))
+		chatGlobalLimit = ratelimit.Parse(os.Getenv("CHAT_RATE_GLOBAL"), ratelimit.New(300, time.Hour))
+	})
+	return chatIPLimit, chatPlayerLimit, chatGlobalLimit
+}
+
+// checkChatRate: ok=false แปลว่าเขียน 429 ไปแล้ว
+// ตรวจต่อ IP/player ก่อน คนที่โดนจำกัดอยู่แล้วจะได้ไม่กินโควตารวม
+func checkChatRate(w http.ResponseWriter, r *http.Request, where string) bool {
+	ipLim, playerLim, globalLim := chatLimiters()
+	ok, wait := ipLim.Allow(clientIP(r))
+	if ok {
+		if pid := requestPlayerID(r); pid != "" {
+			ok, wait = playerLim.Allow(pid)
+		}
+	}
+	if ok {
+		if _, mock := llm.Current().(llm.Mock); !mock {
+			ok, wait = globalLim.Allow("global")
+		}
+	}
+	if ok {
+		return true
+	}
+	log.Printf("%s: rate limited ip=%s player=%s", where, clientIP(r), requestPlayerID(r))
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	writeError(w, http.StatusTooManyRequests, "too many chat requests, try again later")
+	return false
+}
+
+func envFloat(key string) float64 {
+	if f, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && f > 0 {
+		return f
+	}
+	return 0
+}
+
+// usageDay: วันที่ตามเวลาไทย (เก็บเป็น DATE)
+func usageDay(t time.Time) time.Time {
+	y, m, d := t.In(bangkok).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+type budgetStatus struct {
+	Day          string  `json:"day"`
+	TokensUsed   int64   `json:"tokens_used"`
+	TokensLimit  int64   `json:"tokens_limit"` // 0 = ไม่จำกัด
+	CostUSD      float64 `json:"cost_usd"`
+	CostLimitUSD float64 `json:"cost_limit_usd"` // 0 = ไม่จำกัด
+	Exhausted    bool    `json:"exhausted"`
+}
+
+// currentBudget อ่านยอดวันนี้ (ไม่นับ mock) เทียบกับงบ
+func currentBudget(ctx context.Context) (budgetStatus, error) {
+	day := usageDay(time.Now())
+	st := budgetStatus{
+		Day:          day.Format("2006-01-02"),
+		TokensLimit:  int64(envFloat("LLM_DAILY_TOKEN_BUDGET")),
+		CostLimitUSD: envFloat("LLM_DAILY_COST_BUDGET_USD"),
+	}
+	var err error
+	st.TokensUsed, st.CostUSD, err = db.LLMUsageTotal(ctx, day, llm.Mock{}.Name())
+	if err != nil {
+		return st, err
+	}
+	st.Exhausted = (st.TokensLimit > 0 && st.TokensUsed >= st.TokensLimit) ||
+		(st.CostLimitUSD > 0 && st.CostUSD >= st.CostLimitUSD)
+	return st, nil
+}
+
+// chatProvider: provider ของคำขอนี้ งบหมด = llm.Mock ต้องเรียกก่อนเขียน response
+// (คำขอที่กำลังวิ่งพร้อมกันอาจเกินงบไปได้นิดหน่อย)
+func chatProvider(w http.ResponseWriter, r *http.Request) llm.Provider {
+	p := llm.Current()
+	if _, ok := p.(llm.Mock); ok {
+		return p
+	}
+	if os.Getenv("LLM_DAILY_TOKEN_BUDGET") == "" && os.Getenv("LLM_DAILY_COST_BUDGET_USD") == "" {
+		return p
+	}
+	st, err := currentBudget(r.Context())
+	if err != nil {
+		log.Printf("llm budget: %v", err) // อ่านยอดไม่ได้ ใช้ provider จริงต่อ
+		return p
+	}
+	if st.Exhausted {
+		w.Header().Set("X-LLM-Degraded", "budget")
+		return llm.Mock{}
+	}
+	return p
+}
+
+// recordLLMUsage บวกยอดจาก usage ที่ provider บอก (ไม่บอก = ประมาณเอง) แบบไม่ให้ request รอ
+// เรียกทุกครั้งที่ได้ข้อความกลับมา แม้ stream จะขาดกลางทาง (จ่ายไปแล้ว)
+func recordLLMUsage(useCase string, req llm.Request, resp llm.Response) {
+	if resp.Text == "" && resp.Usage == (llm.Usage{}) {
+		return
+	}
+	u := resp.Usage
+	if u == (llm.Usage{}) {
+		for _, m := range req.Messages {
+			u.InputTokens += llm.EstimateTokens(m.Content)
+		}
+		u.OutputTokens = llm.EstimateTokens(resp.Text)
+	}
+	var cost float64
+	if resp.Provider != (llm.Mock{}).Name() {
+		cost = (float64(u.InputTokens)*envFloat("LLM_PRICE_INPUT_PER_1M") +
+			float64(u.OutputTokens)*envFloat("LLM_PRICE_OUTPUT_PER_1M")) / 1e6
+	}
+	row := db.LLMUsage{
+		Day:          usageDay(time.Now()),
+		Provider:     resp.Provider,
+		Model:        resp.Model,
+		UseCase:      useCase,
+		Requests:     1,
+		InputTokens:  int64(u.InputTokens),
+		OutputTokens: int64(u.OutputTokens),
+		CostUSD:      cost,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := db.AddLLMUsage(ctx, row); err != nil {
+			log.Printf("llm usage: %v", err)
+		}
+	}()
+}
+
+type llmUsageResp struct {
+	Day          string  `json:"day"`
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	UseCase      string  `json:"use_case"`
+	Requests     int     `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// GET /api/admin/llm/usage[?days=7] → {provider, budget, usage}
+// usage = ยอดต่อวัน/provider/model/use case ใหม่สุดก่อน (days สูงสุด 90)
+func AdminLLMUsage(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 90 {
+			writeError(w, http.StatusBadRequest, "days must be 1-90")
+			return
+		}
+		days = n
+	}
+	st, err := currentBudget(r.Context())
+	if err != nil {
+		log.Printf("AdminLLMUsage: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load usage")
+		return
+	}
+	rows, err := db.ListLLMUsage(r.Context(), usageDay(time.Now()).AddDate(0, 0, -(days-1)))
+	if err != nil {
+		log.Printf("AdminLLMUsage: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load usage")
+		return
+	}
+	out := make([]llmUsageResp, 0, len(rows))
+	for _, u := range rows {
+		out = append(out, llmUsageResp{
+			Day:          u.Day.Format("2006-01-02"),
+			Provider:     u.Provider,
+			Model:        u.Model,
+			UseCase:      u.UseCase,
+			Requests:     u.Requests,
+			InputTokens:  u.InputTokens,
+			OutputTokens: u.OutputTokens,
+			CostUSD:      u.CostUSD,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"provider": llm.Current().Name(),
+		"budget":   st,
+		"usage":    out,
+	})
+}
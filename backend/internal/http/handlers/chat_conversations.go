@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/llm"
+)
+
+const (
+	chatHistoryMessages = 50 // อ่านจาก DB ต่อรอบ ก่อนตัดตามงบ token (llm.Window)
+	chatTitleRunes      = 60
+)
+
+// chatTurn คือคำขอที่ตรวจแล้ว พร้อมส่งให้ provider
+type chatTurn struct {
+	playerID string
+	conv     *db.ChatConversation // nil = เริ่มใหม่
+	useCase  string
+	message  string
+	req      llm.Request
+}
+
+// prepareChat ตรวจข้อความ โหลดประวัติ (ถ้ามี conversation_id) แล้วตัดให้พอดีงบ token
+// ไม่มี player token = คุยแบบไม่บันทึก (เหมือนเดิม) ok=false แปลว่าเขียน response ไปแล้ว
+func prepareChat(w http.ResponseWriter, r *http.Request) (chatTurn, bool) {
+	if !checkChatRate(w, r, "chat") {
+		return chatTurn{}, false
+	}
+	in, ok := decodeChat(w, r)
+	if !ok {
+		return chatTurn{}, false
+	}
+	t := chatTurn{playerID: requestPlayerID(r), useCase: strings.TrimSpace(in.UseCase), message: in.Message}
+	if t.useCase == "" {
+		t.useCase = llm.UseCaseGeneral
+	}
+	var history []llm.Message
+	if in.ConversationID != 0 {
+		if t.playerID == "" {
+			writeError(w, http.StatusUnauthorized, "player token required")
+			return t, false
+		}
+		c, err := db.GetChatConversation(r.Context(), t.playerID, in.ConversationID)
+		if errors.Is(err, db.ErrNoConversation) {
+			writeError(w, http.StatusNotFound, "conversation not found")
+			return t, false
+		}
+		if err != nil {
+			log.Printf("chat: %v", err)
+			writeError(w, http.StatusInternalServerError, "cannot load conversation")
+			return t, false
+		}
+		msgs, err := db.RecentChatMessages(r.Context(), c.ID, chatHistoryMessages)
+		if err != nil {
+			log.Printf("chat: %v", err)
+			writeError(w, http.StatusInternalServerError, "cannot load conversation")
+			return t, false
+		}
+		for _, m := range msgs {
+			history = append(history, llm.Message{Role: m.Role, Content: m.Content})
+		}
+		t.conv, t.useCase = &c, c.UseCase
+	} else if !llm.ValidUseCase(t.useCase) {
+		writeError(w, http.StatusBadRequest, "unknown use_case")
+		return t, false
+	}
+	history = append(history, llm.Message{Role: llm.RoleUser, Content: t.message})
+	t.req = llm.Request{Messages: llm.Window(llm.SystemPromptFor(t.useCase), history, llm.ContextTokens())}
+	return t, true
+}
+
+// saveChatTurn บันทึกคำถาม/คำตอบ คืน conversation id (0 = ไม่ได้บันทึก)
+// บันทึกไม่ได้ก็ยังส่งคำตอบให้ผู้ใช้ (จ่ายค่า provider ไปแล้ว) แค่ log ไว้
+func saveChatTurn(ctx context.Context, t chatTurn, resp llm.Response) int64 {
+	if t.playerID == "" {
+		return 0
+	}
+	turn := db.ChatTurn{
+		PlayerID: t.playerID,
+		UseCase:  t.useCase,
+		Title:    truncateRunes(t.message, chatTitleRunes),
+		Messages: []db.ChatMessage{
+			{Role: llm.RoleUser, Content: t.message, Tokens: llm.EstimateTokens(t.message)},
+			{Role: llm.RoleAssistant, Content: resp.Text, Tokens: resp.Usage.OutputTokens},
+		},
+	}
+	if t.conv != nil {
+		turn.ConversationID = t.conv.ID
+	}
+	c, err := db.SaveChatTurn(ctx, turn)
+	if err != nil {
+		log.Printf("chat: save turn: %v", err)
+		return 0
+	}
+	return c.ID
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
+
+type chatConversationResp struct {
+	ID        int64  `json:"id"`
+	UseCase   string `json:"use_case"`
+	Title     string `json:"title"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func toChatConversationResp(c db.ChatConversation) chatConversationResp {
+	return chatConversationResp{
+		ID:        c.ID,
+		UseCase:   c.UseCase,
+		Title:     c.Title,
+		CreatedAt: c.CreatedAt.Format(timeLayout),
+		UpdatedAt: c.UpdatedAt.Format(timeLayout),
+	}
+}
+
+type chatMessageResp struct {
+	ID        int64  `json:"id"`
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+// chatPlayer: ทุก endpoint ของบทสนทนาต้องมี player token
+func chatPlayer(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := requestPlayerID(r)
+	if id == "" {
+		writeError(w, http.StatusUnauthorized, "player token required")
+		return "", false
+	}
+	return id, true
+}
+
+func chatConversationIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid conversation id")
+		return 0, false
+	}
+	return id, true
+}
+
+// GET /api/chat/conversations[?limit=20&cursor=...] — ของ player ตัวเอง ใหม่สุดก่อน
+func ListChatConversations(w http.ResponseWriter, r *http.Request) {
+	pid, ok := chatPlayer(w, r)
+	if !ok {
+		return
+	}
+	limit, cur, err := pageParams(r, 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := db.ListChatConversations(r.Context(), pid, cur, limit+1)
+	if err != nil {
+		log.Printf("ListChatConversations: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load conversations")
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(c db.ChatConversation) db.Cursor {
+		return db.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+	out := make([]chatConversationResp, 0, len(rows))
+	for _, c := range rows {
+		out = append(out, toChatConversationResp(c))
+	}
+	writeJSON(w, http.StatusOK, pageResp[chatConversationResp]{Items: out, NextCursor: next, PrevCursor: prev})
+}
+
+// GET /api/chat/conversations/{id}[?limit=50&cursor=...] → {conversation, messages}
+// messages ใหม่สุดก่อน (next_cursor = ย้อนไปข้อความที่เก่ากว่า)
+func GetChatConversation(w http.ResponseWriter, r *http.Request) {
+	pid, ok := chatPlayer(w, r)
+	if !ok {
+		return
+	}
+	id, ok := chatConversationIDParam(w, r)
+	if !ok {
+		return
+	}
+	limit, cur, err := pageParams(r, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	c, err := db.GetChatConversation(r.Context(), pid, id)
+	if errors.Is(err, db.ErrNoConversation) {
+		writeError(w, http.StatusNotFound, "conversation not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetChatConversation: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load conversation")
+		return
+	}
+	rows, err := db.ListChatMessages(r.Context(), id, cur, limit+1)
+	if err != nil {
+		log.Printf("GetChatConversation: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load conversation")
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(m db.ChatMessage) db.Cursor {
+		return db.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	})
+	msgs := make([]chatMessageResp, 0, len(rows))
+	for _, m := range rows {
+		msgs = append(msgs, chatMessageResp{ID: m.ID, Role: m.Role, Content: m.Content, CreatedAt: m.CreatedAt.Format(timeLayout)})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"conversation": toChatConversationResp(c),
+		"messages":     pageResp[chatMessageResp]{Items: msgs, NextCursor: next, PrevCursor: prev},
+	})
+}
+
+// DELETE /api/chat/conversations/{id} — ลบพร้อมข้อความทั้งหมด
+func DeleteChatConversation(w http.ResponseWriter, r *http.Request) {
+	pid, ok := chatPlayer(w, r)
+	if !ok {
+		return
+	}
+	id, ok := chatConversationIDParam(w, r)
+	if !ok {
+		return
+	}
+	err := db.DeleteChatConversation(r.Context(), pid, id)
+	if errors.Is(err, db.ErrNoConversation) {
+		writeError(w, http.StatusNotFound, "conversation not found")
+		return
+	}
+	if err != nil {
+		log.Printf("DeleteChatConversation: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot delete conversation")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
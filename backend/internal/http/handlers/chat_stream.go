@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"my-app-backend/internal/llm"
+)
+
+// ส่ง comment ว่างเป็นระยะ กัน proxy ตัด connection ตอน provider ยังคิดอยู่
+const sseHeartbeat = 15 * time.Second
+
+// sseWriter เขียน event ทีละอันแล้ว flush ทันที (heartbeat กับ delta มาจากคนละ goroutine)
+type sseWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *sseWriter) write(raw string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprint(s.w, raw); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *sseWriter) event(name string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.write("event: " + name + "\ndata: " + string(b) + "\n\n")
+}
+
+// POST /api/chat/stream {message, conversation_id?, use_case?} → text/event-stream
+//
+//	event: delta  data: {"text": "..."}                                     (หลายครั้ง)
+//	event: done   data: {"output_text", "provider", "model", "usage", "conversation_id"}
+//	event: error  data: {"error", "status"}                                 (status เหมือน /api/chat)
+//
+// error ก่อนเริ่ม stream (ข้อความผิด/ไม่พบบทสนทนา) ตอบเป็น JSON ปกติ
+// client ปิด connection = r.Context() ถูกยกเลิก → ยกเลิกคำขอไป provider ด้วย
+// provider ที่ stream ไม่ได้จะได้ delta เดียวทั้งก้อน (llm.Stream)
+func ChatStreamHandler(w http.ResponseWriter, r *http.Request) {
+	t, ok := prepareChat(w, r)
+	if !ok {
+		return
+	}
+
+	provider := chatProvider(w, r)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // nginx: อย่าพัก buffer
+	w.WriteHeader(http.StatusOK)
+	sse := &sseWriter{w: w, rc: http.NewResponseController(w)}
+	if err := sse.write(": open\n\n"); err != nil {
+		log.Printf("chat stream: flush unsupported: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), llm.StreamTimeout())
+	hbDone := make(chan struct{})
+	defer func() {
+		cancel()
+		<-hbDone // ห้ามเขียน w หลัง handler คืนค่า
+	}()
+
+	go func() {
+		defer close(hbDone)
+		t := time.NewTicker(sseHeartbeat)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if sse.write(": ping\n\n") != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	resp, err := llm.Stream(ctx, provider, t.req, func(d string) error {
+		return sse.event("delta", map[string]string{"text": d})
+	})
+	recordLLMUsage(t.useCase, t.req, resp)
+	if r.Context().Err() != nil {
+		return // client ปิดไปแล้ว
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = llm.ErrTimeout // mock/fallback คืน ctx error ตรงๆ
+		}
+		log.Printf("chat stream: %v", err)
+		code, text, _ := llmErrorStatus(err)
+		_ = sse.event("error", map[string]any{"error": text, "status": code})
+		return
+	}
+	_ = sse.event("done", map[string]any{
+		"output_text": resp.Text,
+		"provider":    resp.Provider,
+		"model":       resp.Model,
+		"usage":       resp.Usage,
+
+		"conversation_id": saveChatTurn(r.Context(), t, resp),
+	})
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"my-app-backend/internal/db"
+	"my-app-backend/internal/moderation"
+	"my-app-backend/internal/webhooks"
 )
 
 type Feedback struct {
@@ -14,13 +16,26 @@ type Feedback struct {
 	Contact string `json:"contact"`
 	Message string `json:"message"`
 	Source  string `json:"source"` // optional: ติด tag หน้า/ฟีเจอร์
+
+	Context feedbackClientContext `json:"context"` // optional: ห้อง/โจทย์/หน้าที่เปิดอยู่
+
+	// กันสแปม (ดู feedback_spam.go)
+	FormToken string `json:"formToken"` // จาก GET /api/feedback/form
+	Website   string `json:"website"`   // honeypot: คนจริงไม่เห็นช่องนี้ ต้องว่างเสมอ
 }
 
-// POST /api/feedback
+// POST /api/feedback — JSON หรือ multipart/form-data (แนบรูปใน "screenshots", ดู decodeFeedback)
+// ลำดับตรวจ: rate limit → honeypot → form token → ความยาว → ลิงก์ → ข้อความซ้ำ → คำหยาบ → รูป
 func SaveFeedback(w http.ResponseWriter, r *http.Request) {
-	var f Feedback
-	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
-		http.Error(w, "bad request", http.StatusBadRequest)
+	if !checkFeedbackRate(w, r) {
+		return
+	}
+	f, files, ok := decodeFeedback(w, r)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(f.Website) != "" {
+		rejectFeedback(w, r, f, rejectHoneypot, 0, "")
 		return
 	}
 	f.Name = strings.TrimSpace(f.Name)
@@ -45,33 +60,75 @@ func SaveFeedback(w http.ResponseWriter, r *http.Request) {
 		f.Source = f.Source[:64]
 	}
 
-	if err := db.InsertFeedback(r.Context(), f.Name, f.Contact, f.Message, f.Source); err != nil {
+	hash := messageHash(f.Message)
+	if !checkFeedbackSpam(w, r, f, hash) {
+		return
+	}
+
+	if f.Name, ok = moderate(w, r, moderation.FieldName, f.Name); !ok {
+		return
+	}
+	if f.Message, ok = moderate(w, r, moderation.FieldMessage, f.Message); !ok {
+		return
+	}
+
+	// รูปทำทีหลังสุด (กิน CPU) หลังผ่านการตรวจที่ถูกกว่าแล้ว
+	attachments, ok := storeFeedbackImages(w, r, files)
+	if !ok {
+		return
+	}
+	nf := db.NewFeedback{
+		Name:        f.Name,
+		Contact:     f.Contact,
+		Message:     f.Message,
+		Source:      f.Source,
+		PlayerID:    requestPlayerID(r),
+		IP:          clientIP(r),
+		MessageHash: hash,
+		Context:     feedbackContext(r, f.Context),
+		Attachments: attachments,
+	}
+	id, err := db.InsertFeedback(r.Context(), nf)
+	if err != nil {
+		deleteStoredFiles(r.Context(), attachmentKeys(attachments))
 		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	// ไม่ส่ง contact ออกไปนอกระบบ
+	emitWebhook(webhooks.EventFeedbackCreated, map[string]any{
+		"id":          id,
+		"name":        nf.Name,
+		"message":     nf.Message,
+		"source":      nf.Source,
+		"attachments": len(nf.Attachments),
+		"context":     nf.Context,
+		"admin_url":   fmt.Sprintf("/api/admin/feedback/%d", id),
+	}, nf.PlayerID)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 }
 
-// GET /api/feedback?limit=5
+// GET /api/feedback?limit=5[&cursor=...]
+// สาธารณะ: เฉพาะรายการที่ admin อนุมัติ (published) และไม่มี contact — ฉบับเต็มอยู่ที่ /api/admin/feedback
 func GetFeedbacks(w http.ResponseWriter, r *http.Request) {
-	limit := 5
-	if v := r.URL.Query().Get("limit"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
-			limit = n
-		}
+	limit, cur, err := pageParams(r, 5)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	rows, err := db.GetRecentFeedbacks(r.Context(), limit)
+	rows, err := db.GetRecentFeedbacks(r.Context(), cur, limit+1)
 	if err != nil {
 		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	rows, next, prev := paginate(rows, limit, cur, func(f db.FeedbackRow) db.Cursor {
+		return db.Cursor{CreatedAt: f.CreatedAt, ID: f.ID}
+	})
 
 	type outRow struct {
 		Name      string `json:"name"`
-		Contact   string `json:"contact"`
 		Message   string `json:"message"`
 		Source    string `json:"source"`
 		CreatedAt string `json:"created_at"`
@@ -81,15 +138,13 @@ func GetFeedbacks(w http.ResponseWriter, r *http.Request) {
 	for _, row := range rows {
 		out = append(out, outRow{
 			Name:      row.Name,
-			Contact:   row.Contact,
 			Message:   row.Message,
 			Source:    row.Source,
 			CreatedAt: row.CreatedAt.Format(timeLayout), // ใช้ layout ร่วม
 		})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(out)
+	writeJSON(w, http.StatusOK, pageResp[outRow]{Items: out, NextCursor: next, PrevCursor: prev})
 }
 
 // timeLayout ใช้ร่วม (ถ้ามีไฟล์อื่นประกาศอยู่แล้วข้ามได้)
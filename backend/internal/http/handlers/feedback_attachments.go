@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/storage"
+)
+
+// ด้านยาวของ thumbnail (pixel)
+const feedbackThumbSize = 320
+
+// FEEDBACK_MAX_IMAGES (default 3, 0 = ปิดการแนบรูป)
+func feedbackMaxImages() int {
+	if n, err := strconv.Atoi(os.Getenv("FEEDBACK_MAX_IMAGES")); err == nil && n >= 0 {
+		return n
+	}
+	return 3
+}
+
+// FEEDBACK_MAX_IMAGE_BYTES ต่อไฟล์ (default 5 MiB)
+func feedbackMaxImageBytes() int64 {
+	if n, err := strconv.ParseInt(os.Getenv("FEEDBACK_MAX_IMAGE_BYTES"), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return 5 << 20
+}
+
+// decodeFeedback รับได้ทั้ง JSON (แบบเดิม) และ multipart/form-data
+// multipart: field ชื่อเดียวกับ JSON (context เป็น JSON string) + ไฟล์รูปใน "screenshots" (หลายไฟล์ได้)
+// ok=false แปลว่าเขียน response ไปแล้ว
+func decodeFeedback(w http.ResponseWriter, r *http.Request) (f Feedback, files [][]byte, ok bool) {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "multipart/form-data" {
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return f, nil, false
+		}
+		return f, nil, true
+	}
+
+	maxImages, maxBytes := feedbackMaxImages(), feedbackMaxImageBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxImages)*maxBytes+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			writeError(w, http.StatusRequestEntityTooLarge, "upload too large")
+		} else {
+			writeError(w, http.StatusBadRequest, "invalid multipart form")
+		}
+		return f, nil, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	f = Feedback{
+		Name:      r.FormValue("name"),
+		Contact:   r.FormValue("contact"),
+		Message:   r.FormValue("message"),
+		Source:    r.FormValue("source"),
+		FormToken: r.FormValue("formToken"),
+		Website:   r.FormValue("website"),
+	}
+	if v := r.FormValue("context"); v != "" {
+		_ = json.Unmarshal([]byte(v), &f.Context) // รูปแบบผิดก็ส่ง feedback ได้ แค่ไม่มี context
+	}
+	headers := r.MultipartForm.File["screenshots"]
+	if len(headers) > maxImages {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("too many images (max %d)", maxImages))
+		return f, nil, false
+	}
+	for _, fh := range headers {
+		if fh.Size > maxBytes {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("image too large (max %d bytes)", maxBytes))
+			return f, nil, false
+		}
+		file, err := fh.Open()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "cannot read upload")
+			return f, nil, false
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+		file.Close()
+		if err != nil || int64(len(data)) > maxBytes {
+			writeError(w, http.StatusBadRequest, "cannot read upload")
+			return f, nil, false
+		}
+		files = append(files, data)
+	}
+	return f, files, true
+}
+
+// storeFeedbackImages ตรวจ/เข้ารหัสใหม่/ทำ thumbnail แล้วเก็บลง storage
+// ถ้าพังกลางทาง ลบไฟล์ที่เก็บไปแล้วทิ้ง — ok=false แปลว่าเขียน response ไปแล้ว
+func storeFeedbackImages(w http.ResponseWriter, r *http.Request, files [][]byte) ([]db.Attachment, bool) {
+	imgs := make([]storage.Image, 0, len(files))
+	for i, data := range files {
+		img, err := storage.ProcessImage(data, feedbackThumbSize)
+		switch {
+		case errors.Is(err, storage.ErrUnsupportedImage):
+			writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("screenshot %d: %v", i+1, err))
+			return nil, false
+		case errors.Is(err, storage.ErrImageTooLarge):
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("screenshot %d: %v", i+1, err))
+			return nil, false
+		case err != nil:
+			log.Printf("storeFeedbackImages: %v", err)
+			writeError(w, http.StatusInternalServerError, "cannot process image")
+			return nil, false
+		}
+		imgs = append(imgs, img)
+	}
+
+	st := storage.Current()
+	out := make([]db.Attachment, 0, len(imgs))
+	var stored []string
+	prefix := "feedback/" + time.Now().In(bangkok).Format("2006/01") + "/"
+	for _, img := range imgs {
+		id, err := auth.NewID()
+		if err == nil {
+			a := db.Attachment{
+				StorageKey:  prefix + id + "." + img.Ext,
+				ThumbKey:    prefix + id + ".thumb.jpg",
+				ContentType: img.ContentType,
+				Size:        int64(len(img.Data)),
+				Width:       img.Width,
+				Height:      img.Height,
+			}
+			if err = st.Put(r.Context(), a.StorageKey, img.Data, a.ContentType); err == nil {
+				stored = append(stored, a.StorageKey)
+				if err = st.Put(r.Context(), a.ThumbKey, img.Thumb, "image/jpeg"); err == nil {
+					stored = append(stored, a.ThumbKey)
+					out = append(out, a)
+				}
+			}
+		}
+		if err != nil {
+			log.Printf("storeFeedbackImages: %v", err)
+			deleteStoredFiles(r.Context(), stored)
+			writeError(w, http.StatusInternalServerError, "cannot store image")
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// deleteStoredFiles ลบแบบพยายามให้ครบ (ลบไม่ได้แค่ log ไว้)
+func deleteStoredFiles(ctx context.Context, keys []string) {
+	st := storage.Current()
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if err := st.Delete(context.WithoutCancel(ctx), k); err != nil {
+			log.Printf("deleteStoredFiles %s: %v", k, err)
+		}
+	}
+}
+
+func attachmentKeys(as []db.Attachment) []string {
+	keys := make([]string, 0, 2*len(as))
+	for _, a := range as {
+		keys = append(keys, a.StorageKey, a.ThumbKey)
+	}
+	return keys
+}
+
+type attachmentResp struct {
+	ID          int64  `json:"id"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	URL         string `json:"url"`
+	ThumbURL    string `json:"thumb_url"`
+	CreatedAt   string `json:"created_at"`
+}
+
+func toAttachmentResp(a db.Attachment) attachmentResp {
+	base := fmt.Sprintf("/api/admin/feedback/%d/attachments/%d", a.FeedbackID, a.ID)
+	return attachmentResp{
+		ID:          a.ID,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Width:       a.Width,
+		Height:      a.Height,
+		URL:         base,
+		ThumbURL:    base + "/thumb",
+		CreatedAt:   a.CreatedAt.Format(timeLayout),
+	}
+}
+
+func toAttachmentResps(as []db.Attachment) []attachmentResp {
+	out := make([]attachmentResp, 0, len(as))
+	for _, a := range as {
+		out = append(out, toAttachmentResp(a))
+	}
+	return out
+}
+
+// feedbackAttachments: โหลดไม่ได้ก็แสดง feedback ต่อไปโดยไม่มีรูป (แค่ log)
+func feedbackAttachments(r *http.Request, ids ...int64) map[int64][]db.Attachment {
+	atts, err := db.ListFeedbackAttachments(r.Context(), ids)
+	if err != nil {
+		log.Printf("feedbackAttachments: %v", err)
+		return map[int64][]db.Attachment{}
+	}
+	return atts
+}
+
+// GET /api/admin/feedback/{id}/attachments/{aid}
+func AdminGetFeedbackAttachment(w http.ResponseWriter, r *http.Request) {
+	serveFeedbackAttachment(w, r, false)
+}
+
+// GET /api/admin/feedback/{id}/attachments/{aid}/thumb
+func AdminGetFeedbackAttachmentThumb(w http.ResponseWriter, r *http.Request) {
+	serveFeedbackAttachment(w, r, true)
+}
+
+func serveFeedbackAttachment(w http.ResponseWriter, r *http.Request, thumb bool) {
+	id, ok := feedbackIDParam(w, r)
+	if !ok {
+		return
+	}
+	aid, err := strconv.ParseInt(chi.URLParam(r, "aid"), 10, 64)
+	if err != nil || aid <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid attachment id")
+		return
+	}
+	a, err := db.GetFeedbackAttachment(r.Context(), id, aid)
+	if errors.Is(err, db.ErrNoAttachment) {
+		writeError(w, http.StatusNotFound, "attachment not found")
+		return
+	}
+	if err != nil {
+		log.Printf("serveFeedbackAttachment: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load attachment")
+		return
+	}
+
+	key, ctype := a.StorageKey, a.ContentType
+	if thumb {
+		key, ctype = a.ThumbKey, "image/jpeg"
+	}
+	rc, err := storage.Current().Open(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "file missing")
+		return
+	}
+	if err != nil {
+		log.Printf("serveFeedbackAttachment: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot open file")
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if _, err := io.Copy(w, rc); err != nil {
+		log.Printf("serveFeedbackAttachment: %v", err)
+	}
+}
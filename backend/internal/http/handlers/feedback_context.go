@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+
+	"my-app-backend/internal/db"
+)
+
+// appVersionHeader: frontend ส่งเวอร์ชันของตัวเองมากับทุก request
+const appVersionHeader = "X-App-Version"
+
+// feedbackClientContext คือสิ่งที่ client บอกเองว่ากำลังทำอะไรอยู่ (ไม่บังคับทุกช่อง)
+// multipart ส่งเป็น JSON string ใน field "context"
+type feedbackClientContext struct {
+	Route    string `json:"route"`    // หน้าที่เปิดอยู่ก่อนมาแจ้งปัญหา
+	RoomCode string `json:"roomCode"` // ห้อง party ที่อยู่
+	QuizID   string `json:"quizId"`   // โจทย์ที่เล่นอยู่
+	Locale   string `json:"locale"`   // navigator.language (ไม่ส่ง = ใช้ Accept-Language)
+}
+
+var (
+	localeRe   = regexp.MustCompile(`^[A-Za-z]{2,3}(?:[-_][A-Za-z0-9]{1,8}){0,3}$`)
+	roomCodeRe = regexp.MustCompile(`^[A-Z0-9]{4,8}$`)
+	quizIDRe   = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,64}$`)
+)
+
+// feedbackContext รวมข้อมูลจาก request กับที่ client ส่งมา
+// ค่าที่รูปแบบไม่ถูกทิ้งไปเฉย ๆ (context ไม่ควรทำให้ส่ง feedback ไม่ได้)
+func feedbackContext(r *http.Request, c feedbackClientContext) db.FeedbackContext {
+	out := db.FeedbackContext{
+		RequestID:  chimw.GetReqID(r.Context()),
+		UserAgent:  cleanContextValue(r.UserAgent(), 256),
+		AppVersion: cleanContextValue(r.Header.Get(appVersionHeader), 32),
+	}
+
+	locale := strings.TrimSpace(c.Locale)
+	if locale == "" {
+		// "th-TH,th;q=0.9,en;q=0.8" → "th-TH"
+		locale, _, _ = strings.Cut(r.Header.Get("Accept-Language"), ",")
+		locale, _, _ = strings.Cut(strings.TrimSpace(locale), ";")
+	}
+	if localeRe.MatchString(locale) {
+		out.Locale = locale
+	}
+
+	// เก็บแค่ path — query string อาจมี token
+	route, _, _ := strings.Cut(strings.TrimSpace(c.Route), "?")
+	if strings.HasPrefix(route, "/") && !strings.HasPrefix(route, "//") {
+		out.Route = cleanContextValue(route, 256)
+	}
+	if code := strings.ToUpper(strings.TrimSpace(c.RoomCode)); roomCodeRe.MatchString(code) {
+		out.RoomCode = code
+	}
+	if id := strings.TrimSpace(c.QuizID); quizIDRe.MatchString(id) {
+		out.QuizID = id
+	}
+	return out
+}
+
+// cleanContextValue ตัดอักขระควบคุมออก และจำกัดความยาว (ไม่ตัดกลางตัวอักษร)
+func cleanContextValue(s string, n int) string {
+	s = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s))
+	if len(s) <= n {
+		return s
+	}
+	cut := 0
+	for i := range s {
+		if i > n {
+			break
+		}
+		cut = i
+	}
+	return s[:cut]
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"html"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"my-app-backend/internal/db"
+)
+
+const (
+	minSearchRunes = 2
+	maxSearchRunes = 200
+	snippetRunes   = 160 // ความยาว snippet
+	snippetLead    = 40  // ตัวอักษรก่อนคำแรกที่เจอ
+)
+
+type feedbackSearchHit struct {
+	adminFeedbackResp
+	Score   int    `json:"score"`
+	Snippet string `json:"snippet"` // HTML: escape แล้ว คำที่ตรงครอบด้วย <mark>
+}
+
+// GET /api/admin/feedback/search?q=jigsaw[&status=new&source=...&tag=...&from=...&to=...][&limit=20][&cursor=...]
+// q ใช้ไวยากรณ์แบบ web search ได้: "คำติดกัน", -ไม่เอา, or
+func AdminSearchFeedback(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if n := utf8.RuneCountInString(q); n < minSearchRunes || n > maxSearchRunes {
+		writeError(w, http.StatusBadRequest, "q must be 2-200 characters")
+		return
+	}
+	limit, cur, err := pageParams(r, 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f, ok := adminFeedbackFilter(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.SearchFeedback(r.Context(), q, f, cur, limit+1)
+	if err != nil {
+		log.Printf("AdminSearchFeedback: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot search feedback")
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(h db.FeedbackHit) db.Cursor {
+		return db.Cursor{Score: h.Score, CreatedAt: h.CreatedAt, ID: h.ID}
+	})
+	ids := make([]int64, 0, len(rows))
+	for _, h := range rows {
+		ids = append(ids, h.ID)
+	}
+	atts := feedbackAttachments(r, ids...)
+	terms, _ := db.SearchTerms(q)
+	out := make([]feedbackSearchHit, 0, len(rows))
+	for _, h := range rows {
+		out = append(out, feedbackSearchHit{
+			adminFeedbackResp: toAdminFeedbackResp(h.AdminFeedback, atts[h.ID]),
+			Score:             h.Score,
+			Snippet:           feedbackSnippet(h.Message, terms),
+		})
+	}
+	writeJSON(w, http.StatusOK, pageResp[feedbackSearchHit]{Items: out, NextCursor: next, PrevCursor: prev})
+}
+
+// feedbackSnippet ตัดข้อความรอบคำแรกที่เจอแล้วครอบทุกคำค้นด้วย <mark>
+// เทียบแบบไม่สนตัวพิมพ์ทีละ rune (ไทยไม่มีตัวพิมพ์ใหญ่เล็กอยู่แล้ว)
+// ไม่เจอแบบ substring (ตรงเพราะ stem เช่น crashed/crashes) ได้ต้นข้อความแทน
+func feedbackSnippet(text string, terms []string) string {
+	src := []rune(text)
+	low := make([]rune, len(src))
+	for i, r := range src {
+		low[i] = unicode.ToLower(r)
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	for _, t := range terms {
+		tr := []rune(strings.ToLower(t))
+		if len(tr) == 0 {
+			continue
+		}
+		for i := 0; i+len(tr) <= len(low); {
+			if string(low[i:i+len(tr)]) == string(tr) {
+				spans = append(spans, span{i, i + len(tr)})
+				i += len(tr)
+			} else {
+				i++
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	from := 0
+	if len(spans) > 0 {
+		from = max(spans[0].start-snippetLead, 0)
+	}
+	to := min(from+snippetRunes, len(src))
+	from = max(min(from, to-snippetRunes), 0) // ท้ายข้อความ: ถอยกลับให้ยาวเต็ม snippet
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, sp := range spans {
+		if sp.end <= pos || sp.start >= to {
+			continue
+		}
+		start, end := max(sp.start, pos), min(sp.end, to)
+		b.WriteString(html.EscapeString(string(src[pos:start])))
+		b.WriteString("<mark>" + html.EscapeString(string(src[start:end])) + "</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(src[pos:to])))
+	if to < len(src) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
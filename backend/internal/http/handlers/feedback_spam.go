@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/ratelimit"
+)
+
+// ชื่อฟอร์มใน form token (GET /api/feedback/form)
+const feedbackForm = "feedback"
+
+// เหตุผลที่ปฏิเสธ (เก็บใน feedback_rejections.reason)
+const (
+	rejectHoneypot  = "honeypot"
+	rejectFormToken = "form_token"
+	rejectTooFast   = "too_fast"
+	rejectLinks     = "links"
+	rejectDuplicate = "duplicate"
+)
+
+// limiter อ่าน env ตอนใช้ครั้งแรก (หลัง main โหลด .env แล้ว)
+//
+//	FEEDBACK_RATE_IP     ต่อ IP        default 5/10m
+//	FEEDBACK_RATE_PLAYER ต่อ player id default 10/1h
+var (
+	feedbackLimitOnce   sync.Once
+	feedbackIPLimit     *ratelimit.Limiter
+	feedbackPlayerLimit *ratelimit.Limiter
+)
+
+func feedbackLimiters() (ip, player *ratelimit.Limiter) {
+	feedbackLimitOnce.Do(func() {
+		feedbackIPLimit = ratelimit.Parse(os.Getenv("FEEDBACK_RATE_IP"), ratelimit.New(5, 10*time.Minute))
+		feedbackPlayerLimit = ratelimit.Parse(os.Getenv("FEEDBACK_RATE_PLAYER"), ratelimit.New(10, time.Hour))
+	})
+	return feedbackIPLimit, feedbackPlayerLimit
+}
+
+// FEEDBACK_MAX_LINKS (default 2)
+func feedbackMaxLinks() int {
+	if n, err := strconv.Atoi(os.Getenv("FEEDBACK_MAX_LINKS")); err == nil && n >= 0 {
+		return n
+	}
+	return 2
+}
+
+// FEEDBACK_DUPLICATE_WINDOW (default 10m)
+func feedbackDuplicateWindow() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("FEEDBACK_DUPLICATE_WINDOW")); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|io|co|me|ly|gl|xyz|top|site|online|ru|cn|th)\b(?:/\S*)?`)
+
+// linkStats: จำนวนลิงก์ และสัดส่วนตัวอักษรที่เป็นลิงก์ทั้งหมด
+// นับเป็น rune ไม่ใช่ byte (อักษรไทย 3 byte จะถูกนับน้อยกว่าลิงก์)
+func linkStats(s string) (count int, share float64) {
+	total := utf8.RuneCountInString(s)
+	if total == 0 {
+		return 0, 0
+	}
+	links := linkRe.FindAllString(s, -1)
+	n := 0
+	for _, l := range links {
+		n += utf8.RuneCountInString(l)
+	}
+	return len(links), float64(n) / float64(total)
+}
+
+// messageHash: ข้อความเดียวกันที่ต่างกันแค่ตัวพิมพ์/ช่องว่างได้ hash เดียวกัน
+func messageHash(s string) string {
+	norm := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	sum := sha256.Sum256([]byte(norm))
+	return hex.EncodeToString(sum[:])
+}
+
+// rejectFeedback บันทึก submission ที่ถูกปฏิเสธให้ admin ตรวจ แล้วตอบ error
+// status = 0 แกล้งตอบว่าสำเร็จ (honeypot — ไม่บอก bot ว่าโดนจับได้)
+func rejectFeedback(w http.ResponseWriter, r *http.Request, f Feedback, reason string, status int, msg string) {
+	if err := db.InsertFeedbackRejection(r.Context(), db.FeedbackRejection{
+		Reason:   reason,
+		IP:       clientIP(r),
+		PlayerID: requestPlayerID(r),
+		Name:     truncate(f.Name, 64),
+		Contact:  truncate(f.Contact, 128),
+		Message:  truncate(f.Message, 4000),
+		Source:   truncate(f.Source, 64),
+	}); err != nil {
+		log.Printf("rejectFeedback: %v", err)
+	}
+	if status == 0 {
+		writeJSON(w, http.StatusCreated, map[string]any{"ok": true})
+		return
+	}
+	writeError(w, status, msg)
+}
+
+// checkFeedbackRate: ok=false แปลว่าเขียน 429 ไปแล้ว
+// (ไม่บันทึกลง feedback_rejections — ตอนโดนถล่มจะกลายเป็นเขียน DB แทน)
+func checkFeedbackRate(w http.ResponseWriter, r *http.Request) bool {
+	ipLim, playerLim := feedbackLimiters()
+	ok, wait := ipLim.Allow(clientIP(r))
+	if ok {
+		if pid := requestPlayerID(r); pid != "" {
+			ok, wait = playerLim.Allow(pid)
+		}
+	}
+	if ok {
+		return true
+	}
+	log.Printf("SaveFeedback: rate limited ip=%s player=%s", clientIP(r), requestPlayerID(r))
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	writeError(w, http.StatusTooManyRequests, "too many feedback submissions, try again later")
+	return false
+}
+
+// checkFeedbackSpam ตรวจ form token, ลิงก์ และข้อความซ้ำ (หลัง validate ความยาวแล้ว)
+// ok=false แปลว่าเขียน response ไปแล้ว
+func checkFeedbackSpam(w http.ResponseWriter, r *http.Request, f Feedback, hash string) bool {
+	switch err := auth.VerifyFormToken(f.FormToken, feedbackForm, time.Now()); {
+	case errors.Is(err, auth.ErrFormTooFast):
+		rejectFeedback(w, r, f, rejectTooFast, http.StatusUnprocessableEntity, "form submitted too fast")
+		return false
+	case err != nil:
+		rejectFeedback(w, r, f, rejectFormToken, http.StatusUnprocessableEntity,
+			"invalid or expired form token (GET /api/feedback/form)")
+		return false
+	}
+
+	if n, share := linkStats(f.Message + " " + f.Name + " " + f.Contact); n > feedbackMaxLinks() || (n > 0 && share > 0.5) {
+		rejectFeedback(w, r, f, rejectLinks, http.StatusUnprocessableEntity, "too many links")
+		return false
+	}
+
+	dup, err := db.RecentDuplicateFeedback(r.Context(), hash, requestPlayerID(r), clientIP(r), feedbackDuplicateWindow())
+	if err != nil {
+		log.Printf("SaveFeedback: duplicate check: %v", err) // ตรวจไม่ได้ก็ปล่อยผ่าน
+	}
+	if dup {
+		rejectFeedback(w, r, f, rejectDuplicate, http.StatusConflict, "duplicate feedback")
+		return false
+	}
+	return true
+}
+
+// GET /api/feedback/form — ขอ form token ก่อนส่ง POST /api/feedback
+// ต้องรอ min_age_seconds ก่อนส่ง และใช้ได้ครั้งเดียวภายใน expires_in วินาที
+func GetFeedbackForm(w http.ResponseWriter, r *http.Request) {
+	tok, err := auth.IssueFormToken(feedbackForm, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot issue form token")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"form_token":      tok,
+		"min_age_seconds": int(auth.FormTokenMinAge().Seconds()),
+		"expires_in":      int(auth.FormTokenMaxAge().Seconds()),
+		"honeypot":        "website", // field นี้ต้องส่งเป็นค่าว่างเสมอ (ซ่อนไว้จากคน)
+	})
+}
+
+type feedbackRejectionResp struct {
+	ID        int64  `json:"id"`
+	Reason    string `json:"reason"`
+	IP        string `json:"ip"`
+	PlayerID  string `json:"player_id,omitempty"`
+	Name      string `json:"name"`
+	Contact   string `json:"contact"`
+	Message   string `json:"message"`
+	Source    string `json:"source"`
+	CreatedAt string `json:"created_at"`
+}
+
+// GET /api/admin/feedback/rejections[?reason=links][&limit=20][&cursor=...]
+func AdminListFeedbackRejections(w http.ResponseWriter, r *http.Request) {
+	limit, cur, err := pageParams(r, 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := db.ListFeedbackRejections(r.Context(), strings.TrimSpace(r.URL.Query().Get("reason")), cur, limit+1)
+	if err != nil {
+		log.Printf("AdminListFeedbackRejections: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load rejections")
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(f db.FeedbackRejection) db.Cursor {
+		return db.Cursor{CreatedAt: f.CreatedAt, ID: f.ID}
+	})
+	out := make([]feedbackRejectionResp, 0, len(rows))
+	for _, f := range rows {
+		out = append(out, feedbackRejectionResp{
+			ID: f.ID, Reason: f.Reason, IP: f.IP, PlayerID: f.PlayerID,
+			Name: f.Name, Contact: f.Contact, Message: f.Message, Source: f.Source,
+			CreatedAt: f.CreatedAt.Format(timeLayout),
+		})
+	}
+	writeJSON(w, http.StatusOK, pageResp[feedbackRejectionResp]{Items: out, NextCursor: next, PrevCursor: prev})
+}
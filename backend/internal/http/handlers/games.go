@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"my-app-backend/internal/db"
+)
+
+type gameResp struct {
+	ID              string `json:"id"`
+	DisplayName     string `json:"display_name"`
+	MinScore        int    `json:"min_score"`
+	MaxScore        int    `json:"max_score"`
+	ScoreOrder      string `json:"score_order"` // desc = มากดีกว่า, asc = น้อยดีกว่า
+	RequiresReceipt bool   `json:"requires_receipt"`
+}
+
+func toGameResp(g db.Game) gameResp {
+	return gameResp{
+		ID:              g.ID,
+		DisplayName:     g.DisplayName,
+		MinScore:        g.MinScore,
+		MaxScore:        g.MaxScore,
+		ScoreOrder:      g.ScoreOrder,
+		RequiresReceipt: g.RequiresReceipt,
+	}
+}
+
+// GET /api/games
+func ListGames(w http.ResponseWriter, r *http.Request) {
+	games, err := db.ListGames(r.Context())
+	if err != nil {
+		log.Printf("ListGames: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load games")
+		return
+	}
+	out := make([]gameResp, 0, len(games))
+	for _, g := range games {
+		out = append(out, toGameResp(g))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+// lookupGame หาเกมจาก gamename ที่ส่งมา
+// ok=false แปลว่าเขียน error response ไปแล้ว
+func lookupGame(w http.ResponseWriter, r *http.Request, id string) (db.Game, bool) {
+	g, err := db.GetGame(r.Context(), id)
+	if errors.Is(err, db.ErrNoGame) {
+		writeError(w, http.StatusNotFound, "unknown gamename (see GET /api/games)")
+		return db.Game{}, false
+	}
+	if err != nil {
+		log.Printf("lookupGame: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load game")
+		return db.Game{}, false
+	}
+	return g, true
+}
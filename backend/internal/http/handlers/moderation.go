@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"log"
+	"net"
+	"net/http"
+
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/moderation"
+)
+
+// moderate ตรวจ text ด้วยตัวกรองคำหยาบตามนโยบายของ field แล้วคืนข้อความที่ควรเก็บ
+// ok=false แปลว่าเขียน error response ไปแล้ว (action = reject)
+func moderate(w http.ResponseWriter, r *http.Request, field, text string) (string, bool) {
+	d := moderation.Review(field, text)
+	switch d.Action {
+	case moderation.ActionReject:
+		writeError(w, http.StatusUnprocessableEntity, field+" contains inappropriate language")
+		return "", false
+	case moderation.ActionFlag:
+		// บันทึกไม่ได้ก็ยังปล่อยผ่าน ไม่ให้ผู้ใช้เสีย request เพราะระบบตรวจ
+		if err := db.InsertModerationFlag(r.Context(), db.ModerationFlag{
+			Field:    field,
+			Source:   r.URL.Path,
+			Content:  text,
+			Terms:    d.Terms,
+			Action:   string(d.Action),
+			PlayerID: requestPlayerID(r),
+			IP:       clientIP(r),
+		}); err != nil {
+			log.Printf("moderate: %v", err)
+		}
+	}
+	return d.Text, true
+}
+
+// clientIP: appmw.RealIP แก้ RemoteAddr ให้แล้วถ้ามาจาก proxy ที่เชื่อถือ (ปลอม X-Forwarded-For ไม่ได้)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"my-app-backend/internal/db"
+)
+
+// cursor ส่งให้ client เป็น base64url ของ JSON — client ไม่ต้องรู้โครงสร้างข้างใน
+func encodeCursor(c db.Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*db.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var c db.Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID <= 0 {
+		return nil, errors.New("invalid cursor")
+	}
+	return &c, nil
+}
+
+// pageResp คือ envelope มาตรฐานของ endpoint ที่แบ่งหน้า
+type pageResp[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// pageParams อ่าน ?limit= และ ?cursor= (limit สูงสุด 100)
+func pageParams(r *http.Request, defLimit int) (int, *db.Cursor, error) {
+	limit := defLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	cur, err := decodeCursor(r.URL.Query().Get("cursor"))
+	return limit, cur, err
+}
+
+// paginate ตัดแถวที่ดึงเกินมา 1 แถว (ใช้เช็กว่ายังมีหน้าถัดไป) แล้วสร้าง cursor ไป/กลับ
+// rows ต้องดึงมาด้วย limit+1 และเรียงตามลำดับที่แสดงผลแล้ว
+func paginate[T any](rows []T, limit int, cur *db.Cursor, key func(T) db.Cursor) (page []T, next, prev string) {
+	before := cur != nil && cur.Before
+	more := len(rows) > limit
+	if more {
+		if before {
+			rows = rows[1:]
+		} else {
+			rows = rows[:limit]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+	if more || before {
+		c := key(rows[len(rows)-1])
+		next = encodeCursor(c)
+	}
+	if (before && more) || (!before && cur != nil) {
+		c := key(rows[0])
+		c.Before = true
+		prev = encodeCursor(c)
+	}
+	return rows, next, prev
+}
@@ -14,9 +14,15 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
+
+	"my-app-backend/internal/achievements"
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/config"
+	"my-app-backend/internal/db"
 )
 
 /*
@@ -83,22 +89,24 @@ const (
 
 // Room represents a multiplayer game room
 type Room struct {
-	ID         int64      `json:"id"`          // Unique room identifier
-	Code       string     `json:"code"`        // 6-character room code for joining
-	OwnerName  string     `json:"owner_name"`  // Name of the room creator
-	Status     roomStatus `json:"status"`      // Current room status
-	MaxPlayers int        `json:"max_players"` // Maximum number of players allowed
-	CreatedAt  time.Time  `json:"-"`           // Room creation timestamp (not sent to client)
+	ID           int64      `json:"id"`                      // Unique room identifier
+	Code         string     `json:"code"`                    // 6-character room code for joining
+	OwnerName    string     `json:"owner_name"`              // Name of the room creator
+	Status       roomStatus `json:"status"`                  // Current room status
+	MaxPlayers   int        `json:"max_players"`             // Maximum number of players allowed
+	RoundSeconds int        `json:"round_seconds,omitempty"` // Round duration set by the host (0 = party default)
+	CreatedAt    time.Time  `json:"-"`                       // Room creation timestamp (not sent to client)
 }
 
 // Player represents a participant in a game room
 type Player struct {
-	ID      int64  `json:"id"`       // Unique player identifier within the room
-	Name    string `json:"name"`     // Player's display name
-	IsOwner bool   `json:"is_owner"` // Whether this player is the room owner
-	IsReady bool   `json:"is_ready"` // Whether the player is ready to start
-	Score   int    `json:"score"`    // Player's current score
-	IsOut   bool   `json:"is_out"`   // Whether the player is eliminated
+	ID       int64  `json:"id"`                  // Unique player identifier within the room
+	PlayerID string `json:"player_id,omitempty"` // Guest/account id when joined with X-Player-Token
+	Name     string `json:"name"`                // Player's display name
+	IsOwner  bool   `json:"is_owner"`            // Whether this player is the room owner
+	IsReady  bool   `json:"is_ready"`            // Whether the player is ready to start
+	Score    int    `json:"score"`               // Player's current score
+	IsOut    bool   `json:"is_out"`              // Whether the player is eliminated
 }
 
 type RoundPayload struct {
@@ -112,10 +120,14 @@ type RoundPayload struct {
 
 // leaderboard named type
 type LeaderItem struct {
-	Name  string `json:"name"`
-	Score int    `json:"score"`
+	Name    string `json:"name"`
+	Score   int    `json:"score"`
+	Receipt string `json:"receipt,omitempty"` // ส่งคู่กับ POST /api/scores (เกม partyGameID ต้องมี receipt)
 }
 
+// partyGameID คือ gamename ของคะแนนโหมด party ในตาราง games
+const partyGameID = "DogPuzzleParty"
+
 type hubMsg struct {
 	Type    string        `json:"type"`
 	Room    *Room         `json:"room,omitempty"`
@@ -123,12 +135,13 @@ type hubMsg struct {
 	Round   *RoundPayload `json:"round,omitempty"`
 
 	// events
-	Name        string       `json:"name,omitempty"`
-	Guess       string       `json:"guess,omitempty"`
-	Correct     *bool        `json:"correct,omitempty"`
-	Seconds     int          `json:"seconds,omitempty"`
-	Winner      *Player      `json:"winner,omitempty"`
-	Leaderboard []LeaderItem `json:"leaderboard,omitempty"`
+	Name        string              `json:"name,omitempty"`
+	Guess       string              `json:"guess,omitempty"`
+	Correct     *bool               `json:"correct,omitempty"`
+	Seconds     int                 `json:"seconds,omitempty"`
+	Winner      *Player             `json:"winner,omitempty"`
+	Leaderboard []LeaderItem        `json:"leaderboard,omitempty"`
+	Badge       *achievements.Badge `json:"badge,omitempty"` // achievement_unlocked
 
 	// ✅ แจ้ง error ของรอบ/ระบบ
 	Error string `json:"error,omitempty"`
@@ -142,6 +155,7 @@ type roomState struct {
 	seconds     int
 	roundSolved bool   // ✅ มีคนตอบถูกในรอบนี้แล้วหรือยัง
 	category    string // ✅ หมวดหมู่ของเกม
+	ownerID     string // player_id ของเจ้าของห้อง ("" = สร้างแบบไม่มี token)
 }
 
 var rooms sync.Map // code -> *roomState
@@ -255,6 +269,26 @@ func validateRoomState(st *roomState) error {
 	return nil
 }
 
+// recordPartyResults เก็บผลของผู้เล่นที่มี player_id (ทำเบื้องหลัง เรียกตอนถือ st.mu ได้)
+func recordPartyResults(code string, players []*Player, champ *Player) {
+	var results []db.PartyResult
+	for _, p := range players {
+		if p.PlayerID != "" {
+			results = append(results, db.PartyResult{PlayerID: p.PlayerID, Score: p.Score, Won: p == champ})
+		}
+	}
+	if len(results) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := db.InsertPartyResults(ctx, code, results); err != nil {
+			log.Printf("recordPartyResults %s: %v", code, err)
+		}
+	}()
+}
+
 // Helper function to clean up a room after game ends
 func cleanupRoom(code string) {
 	// Remove room from active rooms
@@ -265,14 +299,47 @@ func cleanupRoom(code string) {
 
 	log.Printf("Room %s cleaned up after game ended", code)
 }
-func findPlayer(arr []*Player, name string) *Player {
+
+// sameSeat: ที่นั่งที่ผูก player_id แล้วใช้ได้เฉพาะ token ของคนนั้น
+// ที่นั่งแบบไม่มี token เทียบด้วยชื่อเหมือนเดิม
+func sameSeat(p *Player, playerID, name string) bool {
+	if playerID != "" || p.PlayerID != "" {
+		return p.PlayerID == playerID
+	}
+	return strings.EqualFold(p.Name, name)
+}
+func findPlayer(arr []*Player, playerID, name string) *Player {
 	for _, p := range arr {
-		if strings.EqualFold(p.Name, name) {
+		if sameSeat(p, playerID, name) {
 			return p
 		}
 	}
 	return nil
 }
+
+// rebindSeats ย้ายที่นั่งในห้องที่ยังเล่นอยู่จาก player เก่าไปเป็นบัญชีใหม่ (หลัง merge guest)
+func rebindSeats(oldID, newID, name string) {
+	rooms.Range(func(_, value any) bool {
+		st := value.(*roomState)
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		changed := false
+		for _, p := range st.players {
+			if p.PlayerID == oldID {
+				p.PlayerID, p.Name = newID, name
+				changed = true
+			}
+		}
+		if st.ownerID == oldID {
+			st.ownerID, st.room.OwnerName = newID, name
+		}
+		if changed {
+			wsHubBroadcast(st.room.Code, hubMsg{Type: "players_updated", Players: clonePlayers(st.players)})
+		}
+		return true
+	})
+}
+
 func clonePlayers(arr []*Player) []*Player {
 	out := make([]*Player, 0, len(arr))
 	for _, p := range arr {
@@ -297,12 +364,13 @@ func ListRooms(w http.ResponseWriter, r *http.Request) {
 		// Only show waiting rooms
 		if room.Status == statusWaiting {
 			roomInfo := map[string]any{
-				"id":           room.ID,
-				"code":         room.Code,
-				"owner_name":   room.OwnerName,
-				"status":       room.Status,
-				"max_players":  room.MaxPlayers,
-				"player_count": playerCount, // Add current player count
+				"id":            room.ID,
+				"code":          room.Code,
+				"owner_name":    room.OwnerName,
+				"status":        room.Status,
+				"max_players":   room.MaxPlayers,
+				"round_seconds": room.RoundSeconds,
+				"player_count":  playerCount, // Add current player count
 			}
 			roomsList = append(roomsList, roomInfo)
 		}
@@ -312,38 +380,46 @@ func ListRooms(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"rooms": roomsList})
 }
 
-// POST /api/rooms {ownerName, maxPlayers}
+// POST /api/rooms {ownerName, maxPlayers, category, roundSeconds}
 func CreateRoom(w http.ResponseWriter, r *http.Request) {
 	var in struct {
-		OwnerName  string `json:"ownerName"`
-		MaxPlayers int    `json:"maxPlayers"`
-		Category   string `json:"category"`
+		OwnerName    string `json:"ownerName"`
+		MaxPlayers   int    `json:"maxPlayers"`
+		Category     string `json:"category"`
+		RoundSeconds int    `json:"roundSeconds"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 		http.Error(w, "bad request", http.StatusBadRequest)
 		return
 	}
 	in.OwnerName = strings.TrimSpace(in.OwnerName)
-	if in.OwnerName == "" {
-		http.Error(w, "ownerName required", http.StatusBadRequest)
+	ownerID, ownerName, ok := claimName(w, r, in.OwnerName)
+	if !ok {
 		return
 	}
+	in.OwnerName = ownerName
 	if in.MaxPlayers < 2 || in.MaxPlayers > 4 {
 		in.MaxPlayers = 4
 	}
 	if in.Category == "" {
 		in.Category = "สัตว์" // Default to animals
 	}
+	if in.RoundSeconds > 0 {
+		in.RoundSeconds = config.ClampRoundSeconds(in.RoundSeconds)
+	} else {
+		in.RoundSeconds = 0
+	}
 
 	code := codeGen()
 	room := &Room{
-		Code:       code,
-		OwnerName:  in.OwnerName,
-		Status:     statusWaiting,
-		MaxPlayers: in.MaxPlayers,
-		CreatedAt:  time.Now(),
-	}
-	st := &roomState{room: room, players: []*Player{}, category: in.Category}
+		Code:         code,
+		OwnerName:    in.OwnerName,
+		Status:       statusWaiting,
+		MaxPlayers:   in.MaxPlayers,
+		RoundSeconds: in.RoundSeconds,
+		CreatedAt:    time.Now(),
+	}
+	st := &roomState{room: room, players: []*Player{}, category: in.Category, ownerID: ownerID}
 	rooms.Store(code, st)
 
 	writeJSON(w, http.StatusOK, map[string]any{"room": room})
@@ -367,13 +443,14 @@ func JoinRoom(w http.ResponseWriter, r *http.Request) {
 	}
 
 	in.Name = strings.TrimSpace(in.Name)
-	if in.Name == "" {
-		writeError(w, http.StatusBadRequest, "Player name is required")
+	playerID, name, ok := claimName(w, r, in.Name)
+	if !ok {
 		return
 	}
+	in.Name = name
 
 	// Validate name length and characters
-	if len(in.Name) > 20 {
+	if utf8.RuneCountInString(in.Name) > maxDisplayName {
 		writeError(w, http.StatusBadRequest, "Player name too long (max 20 characters)")
 		return
 	}
@@ -386,9 +463,9 @@ func JoinRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Remove any existing player with the same name (handle re-joining)
+	// Remove any existing seat of this player (handle re-joining)
 	for i, p := range st.players {
-		if strings.EqualFold(p.Name, in.Name) {
+		if sameSeat(p, playerID, in.Name) {
 			st.players = append(st.players[:i], st.players[i+1:]...)
 			break
 		}
@@ -399,11 +476,12 @@ func JoinRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isOwner := len(st.players) == 0 && strings.EqualFold(st.room.OwnerName, in.Name)
+	isOwner := len(st.players) == 0 && st.ownerID == playerID && strings.EqualFold(st.room.OwnerName, in.Name)
 	p := &Player{
-		ID:      int64(len(st.players) + 1),
-		Name:    in.Name,
-		IsOwner: isOwner,
+		ID:       int64(len(st.players) + 1),
+		PlayerID: playerID,
+		Name:     in.Name,
+		IsOwner:  isOwner,
 	}
 	st.players = append(st.players, p)
 
@@ -435,7 +513,8 @@ func ReadyRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	in.Name = strings.TrimSpace(in.Name)
-	if in.Name == "" {
+	playerID := requestPlayerID(r)
+	if in.Name == "" && playerID == "" {
 		http.Error(w, "name required", http.StatusBadRequest)
 		return
 	}
@@ -447,7 +526,7 @@ func ReadyRoom(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "already started", http.StatusConflict)
 		return
 	}
-	p := findPlayer(st.players, in.Name)
+	p := findPlayer(st.players, playerID, in.Name)
 	if p == nil {
 		http.Error(w, "not in room", http.StatusNotFound)
 		return
@@ -458,7 +537,6 @@ func ReadyRoom(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
-// POST /api/rooms/{code}/start {ownerName}
 // POST /api/rooms/{code}/start {ownerName}
 func StartRoom(w http.ResponseWriter, r *http.Request) {
 	code := roomCode(r)
@@ -475,10 +553,12 @@ func StartRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	playerID := requestPlayerID(r)
+
 	st.mu.Lock()
 	defer st.mu.Unlock()
 
-	owner := findPlayer(st.players, in.OwnerName)
+	owner := findPlayer(st.players, playerID, strings.TrimSpace(in.OwnerName))
 	if owner == nil || !owner.IsOwner {
 		http.Error(w, "only owner can start", http.StatusForbidden)
 		return
@@ -559,7 +639,8 @@ func GuessRoom(w http.ResponseWriter, r *http.Request) {
 	}
 	in.Name = strings.TrimSpace(in.Name)
 	in.Guess = strings.TrimSpace(in.Guess)
-	if in.Name == "" || in.Guess == "" {
+	playerID := requestPlayerID(r)
+	if (in.Name == "" && playerID == "") || in.Guess == "" {
 		http.Error(w, "invalid", http.StatusBadRequest)
 		return
 	}
@@ -571,7 +652,7 @@ func GuessRoom(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "not playing", http.StatusConflict)
 		return
 	}
-	p := findPlayer(st.players, in.Name)
+	p := findPlayer(st.players, playerID, in.Name)
 	if p == nil {
 		http.Error(w, "not in room", http.StatusNotFound)
 		return
@@ -634,13 +715,15 @@ func LeaveRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	in.Name = strings.TrimSpace(in.Name)
+	playerID := requestPlayerID(r)
 
 	st.mu.Lock()
 	defer st.mu.Unlock()
 
 	// Find and remove the player from the room
 	for i, p := range st.players {
-		if strings.EqualFold(p.Name, in.Name) {
+		if sameSeat(p, playerID, in.Name) {
+			in.Name = p.Name
 			st.players = append(st.players[:i], st.players[i+1:]...)
 			break
 		}
@@ -711,7 +794,7 @@ func RoomWS(w http.ResponseWriter, r *http.Request) {
 // ---------- Round & Timer ----------
 
 func startRoundLocked(ctx context.Context, st *roomState, round int) {
-	q, err := getQuiz(ctx, 1, st.category) // level = 1 (ปรับได้)
+	q, err := getQuiz(ctx, 1, st.category, st.room.RoundSeconds) // level = 1 (ปรับได้)
 	if err != nil {
 		// ❌ ดึงคำถามไม่ได้ (เช่น พอร์ตผิด / โปรเซสยังไม่ตื่น)
 		log.Printf("[party] getQuiz error: %v", err)
@@ -728,12 +811,17 @@ func startRoundLocked(ctx context.Context, st *roomState, round int) {
 		})
 		return
 	}
+	// เวลาในรอบมาจาก quiz endpoint ตัวเดียวกับที่ใช้คำนวณ exp ของ token
+	seconds := q.Seconds
+	if seconds <= 0 {
+		seconds = int(q.Exp - time.Now().Unix())
+	}
 	st.round = &RoundPayload{
 		RoundNo:   round,
 		QuizID:    q.ID,
 		QuizToken: q.Token,
 		QuizExp:   q.Exp,
-		Seconds:   60,
+		Seconds:   seconds,
 		Level:     1,
 	}
 	st.seconds = st.round.Seconds
@@ -766,7 +854,11 @@ func tickTimer(st *roomState, roundNo int) {
 
 				leader := make([]LeaderItem, 0, len(st.players))
 				for _, p := range st.players {
-					leader = append(leader, LeaderItem{Name: p.Name, Score: p.Score})
+					item := LeaderItem{Name: p.Name, Score: p.Score}
+					if rc, err := auth.IssueScoreReceipt(partyGameID, p.Name, p.Score, time.Now()); err == nil {
+						item.Receipt = rc
+					}
+					leader = append(leader, item)
 				}
 				sort.Slice(leader, func(i, j int) bool { return leader[i].Score > leader[j].Score })
 
@@ -782,6 +874,13 @@ func tickTimer(st *roomState, roundNo int) {
 					}
 				}
 
+				recordPartyResults(st.room.Code, st.players, champ)
+				if champ != nil && champ.PlayerID != "" {
+					emitAchievement(achievements.Event{
+						Kind: achievements.PartyWon, PlayerID: champ.PlayerID, Name: champ.Name, RoomCode: st.room.Code,
+					})
+				}
+
 				// Broadcast game over event
 				wsHubBroadcast(st.room.Code, hubMsg{
 					Type:        "game_over",
@@ -810,15 +909,19 @@ type quizResp struct {
 	ID        string `json:"id"`
 	Token     string `json:"token"`
 	Exp       int64  `json:"exp"`
+	Seconds   int    `json:"seconds"`
 	HintCount int    `json:"hintCount"`
 }
 
-func getQuiz(ctx context.Context, level int, category string) (*quizResp, error) {
+func getQuiz(ctx context.Context, level int, category string, seconds int) (*quizResp, error) {
 	// ✅ ใช้พอร์ตจริงของโปรเซส
-	url := quizBaseURL + "/api/quiz?level=" + strconv.Itoa(level)
+	url := quizBaseURL + "/api/quiz?mode=" + config.ModeParty + "&level=" + strconv.Itoa(level)
 	if category != "" {
 		url += "&category=" + category
 	}
+	if seconds > 0 {
+		url += "&seconds=" + strconv.Itoa(seconds)
+	}
 
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	res, err := httpQuizClient.Do(req)
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+)
+
+// Leaderboard periods follow Thai calendar days (Asia/Bangkok, UTC+7).
+const (
+	periodDaily   = "daily"
+	periodWeekly  = "weekly"
+	periodMonthly = "monthly"
+	periodAll     = "all"
+)
+
+// bangkok: distroless image ไม่มี tzdata ติดมา ถ้าโหลดไม่ได้ใช้ fixed zone แทน (ไทยไม่มี DST)
+var bangkok = func() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Bangkok"); err == nil {
+		return loc
+	}
+	return time.FixedZone("Asia/Bangkok", 7*60*60)
+}()
+
+// parsePeriod normalises ?period=; ok=false for unknown values.
+func parsePeriod(v string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case periodDaily, "day", "today":
+		return periodDaily, true
+	case periodWeekly, "week":
+		return periodWeekly, true
+	case periodMonthly, "month":
+		return periodMonthly, true
+	case periodAll, "alltime", "all-time", "all_time", "":
+		return periodAll, true
+	}
+	return "", false
+}
+
+// periodBounds returns [start, reset) of the period containing now.
+// For periodAll both are zero. Weeks start on Monday.
+func periodBounds(period string, now time.Time) (start, reset time.Time) {
+	t := now.In(bangkok)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, bangkok)
+	switch period {
+	case periodDaily:
+		return day, day.AddDate(0, 0, 1)
+	case periodWeekly:
+		offset := (int(day.Weekday()) + 6) % 7 // Monday = 0
+		start = day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7)
+	case periodMonthly:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, bangkok)
+		return start, start.AddDate(0, 1, 0)
+	}
+	return time.Time{}, time.Time{}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/moderation"
+)
+
+const maxDisplayName = 20 // ตัวอักษร (rune) ให้เท่ากับชื่อในห้อง party
+
+var errBadPlayerToken = errors.New("invalid player token")
+
+// requestPlayerID คือ player ที่ middleware.Authenticate ยืนยันแล้ว ("" = ไม่ระบุตัวตน)
+func requestPlayerID(r *http.Request) string {
+	id, _ := auth.FromContext(r.Context())
+	return id.PlayerID
+}
+
+// requestPlayer คืน player ของ token (nil = ไม่ได้ส่ง token มา)
+func requestPlayer(r *http.Request) (*db.Player, error) {
+	id := requestPlayerID(r)
+	if id == "" {
+		return nil, nil
+	}
+	p, err := db.GetPlayer(r.Context(), id)
+	if errors.Is(err, db.ErrNoPlayer) {
+		return nil, errBadPlayerToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// claimName หาชื่อที่จะใช้แสดงของ request นี้
+//   - มี token → ใช้ display name ของ player (ไม่สน name ที่ส่งมา)
+//   - ไม่มี token → ใช้ name ได้ ถ้าไม่ใช่ชื่อที่ player คนอื่นจองไว้
+//
+// ok=false แปลว่าเขียน error response ไปแล้ว
+func claimName(w http.ResponseWriter, r *http.Request, name string) (playerID, display string, ok bool) {
+	p, err := requestPlayer(r)
+	if errors.Is(err, errBadPlayerToken) {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return "", "", false
+	}
+	if err != nil {
+		log.Printf("claimName: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot verify player")
+		return "", "", false
+	}
+	if p != nil {
+		return p.ID, p.DisplayName, true
+	}
+
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name required")
+		return "", "", false
+	}
+	if name, ok = moderate(w, r, moderation.FieldName, name); !ok {
+		return "", "", false
+	}
+	reserved, err := db.IsNameReserved(r.Context(), name)
+	if err != nil {
+		log.Printf("claimName: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot verify player")
+		return "", "", false
+	}
+	if reserved {
+		writeError(w, http.StatusConflict, "name is reserved by another player")
+		return "", "", false
+	}
+	return "", name, true
+}
+
+type playerResp struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	Kind        string `json:"kind"`
+	CreatedAt   string `json:"created_at"`
+	Token       string `json:"token,omitempty"`
+}
+
+func toPlayerResp(p db.Player) playerResp {
+	return playerResp{ID: p.ID, DisplayName: p.DisplayName, Kind: p.Kind, CreatedAt: p.CreatedAt.Format(timeLayout)}
+}
+
+// POST /api/players/guest {name}
+// สร้าง guest player + device token (client เก็บ token ไว้ส่งใน X-Player-Token)
+func CreateGuestPlayer(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		writeError(w, http.StatusBadRequest, "name required")
+		return
+	}
+	if utf8.RuneCountInString(in.Name) > maxDisplayName {
+		writeError(w, http.StatusBadRequest, "name too long (max 20 characters)")
+		return
+	}
+	name, ok := moderate(w, r, moderation.FieldName, in.Name)
+	if !ok {
+		return
+	}
+
+	id, err := auth.NewID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot generate id")
+		return
+	}
+	p, err := db.CreateGuestPlayer(r.Context(), id, name)
+	if errors.Is(err, db.ErrNameTaken) {
+		writeError(w, http.StatusConflict, "name already taken")
+		return
+	}
+	if err != nil {
+		log.Printf("CreateGuestPlayer: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot create player")
+		return
+	}
+
+	out := toPlayerResp(p)
+	out.Token = auth.IssueDeviceToken(p.ID)
+	writeJSON(w, http.StatusCreated, out)
+}
+
+// GET /api/players/me (X-Player-Token หรือ Authorization: Bearer)
+func GetMe(w http.ResponseWriter, r *http.Request) {
+	p, err := requestPlayer(r)
+	if errors.Is(err, errBadPlayerToken) {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("GetMe: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load player")
+		return
+	}
+	if p == nil {
+		writeError(w, http.StatusUnauthorized, "player token required")
+		return
+	}
+	writeJSON(w, http.StatusOK, toPlayerResp(*p))
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+)
+
+// requestAdmin คืนชื่อ admin ถ้า request มี X-Admin-Key ที่ถูกต้อง ("" = ไม่ใช่ admin)
+func requestAdmin(r *http.Request) string {
+	if name, ok := auth.AdminFromContext(r.Context()); ok {
+		return name
+	}
+	name, _ := auth.AdminName(strings.TrimSpace(r.Header.Get(auth.AdminKeyHeader)))
+	return name
+}
+
+// privacySubject: {id} ที่ request นี้มีสิทธิ์ดู/ลบข้อมูล — ตัวเอง ("me" หรือ id ตัวเอง) หรือ admin
+// ok=false แปลว่าเขียน error response ไปแล้ว
+func privacySubject(w http.ResponseWriter, r *http.Request) (playerID, actor, actorKind string, ok bool) {
+	id := chi.URLParam(r, "id")
+	self := requestPlayerID(r)
+	if id == "me" || (self != "" && id == self) {
+		if self == "" {
+			writeError(w, http.StatusUnauthorized, "player token required")
+			return "", "", "", false
+		}
+		return self, self, "player", true
+	}
+	if admin := requestAdmin(r); admin != "" {
+		return id, admin, "admin", true
+	}
+	writeError(w, http.StatusForbidden, "can only access your own data")
+	return "", "", "", false
+}
+
+// GET /api/players/{id}/export — ข้อมูลทั้งหมดที่เก็บเกี่ยวกับผู้เล่น (ตัวเอง หรือ X-Admin-Key)
+func ExportPlayerData(w http.ResponseWriter, r *http.Request) {
+	id, actor, kind, ok := privacySubject(w, r)
+	if !ok {
+		return
+	}
+	data, err := db.ExportPlayerData(r.Context(), id)
+	if errors.Is(err, db.ErrNoPlayer) {
+		writeError(w, http.StatusNotFound, "player not found")
+		return
+	}
+	if err != nil {
+		log.Printf("ExportPlayerData: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot export data")
+		return
+	}
+	if kind == "admin" {
+		log.Printf("ExportPlayerData: admin %s exported player %s", actor, id)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", `attachment; filename="player-`+id+`.json"`)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"exported_at": time.Now().Format(timeLayout),
+		"data":        data,
+	})
+}
+
+// DELETE /api/players/{id}[?contact=...] — ลบ/ทำให้ข้อมูลไม่ระบุตัวตน (ตัวเอง หรือ X-Admin-Key)
+// contact ใช้ได้เฉพาะ admin: ลบ feedback แบบไม่ระบุตัวตนที่ใช้ contact นี้ด้วย
+func ErasePlayerData(w http.ResponseWriter, r *http.Request) {
+	id, actor, kind, ok := privacySubject(w, r)
+	if !ok {
+		return
+	}
+	contact := strings.TrimSpace(r.URL.Query().Get("contact"))
+	if contact != "" && kind != "admin" {
+		writeError(w, http.StatusForbidden, "contact erasure requires admin")
+		return
+	}
+	suffix, err := auth.NewID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot generate id")
+		return
+	}
+
+	res, err := db.ErasePlayer(r.Context(), db.Erasure{
+		PlayerID:  id,
+		Actor:     actor,
+		ActorKind: kind,
+		Contact:   contact,
+		Alias:     "(deleted #" + suffix[:6] + ")",
+	})
+	if errors.Is(err, db.ErrNoPlayer) {
+		writeError(w, http.StatusNotFound, "player not found")
+		return
+	}
+	if err != nil {
+		log.Printf("ErasePlayerData: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot erase data")
+		return
+	}
+
+	// access token ที่ออกไปแล้วใช้ไม่ได้ทันที + ล้างสถิติที่ cache ไว้
+	for _, sid := range res.SessionIDs {
+		auth.RevokeSession(sid)
+	}
+	statsMu.Lock()
+	delete(statsCache, id)
+	statsMu.Unlock()
+	deleteStoredFiles(r.Context(), res.FileKeys)
+
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "player_id": id, "counts": res.Counts})
+}
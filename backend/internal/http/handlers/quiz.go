@@ -16,6 +16,8 @@ import (
 	"strings"
 	"time"
 
+	"my-app-backend/internal/achievements"
+	"my-app-backend/internal/config"
 	"my-app-backend/internal/db"
 )
 
@@ -26,6 +28,7 @@ type QuizResp struct {
 	HintCount int    `json:"hintCount"`
 	Token     string `json:"token"` // HMAC(secret, answer|id|exp)
 	Exp       int64  `json:"exp"`   // unix seconds
+	Seconds   int    `json:"seconds"`
 }
 
 type HintReq struct {
@@ -81,6 +84,30 @@ func poolTierForLevel(level int) int {
 	return 1
 }
 
+// roundSeconds: ?seconds= ขอเวลาเองได้ แต่ต้องอยู่ในขอบเขตที่ config กำหนด
+// ถ้าไม่ส่งมาใช้ค่า default ของ mode/tier นั้น
+func roundSeconds(r *http.Request, mode string, tier int) int {
+	if v := r.URL.Query().Get("seconds"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return config.ClampRoundSeconds(n)
+		}
+	}
+	return config.RoundSeconds(mode, tier)
+}
+
+// recordQuizRun เก็บประวัติการเล่นถ้า request มี X-Player-Token
+// (ไม่ให้ error ตรงนี้ทำให้เกมเล่นไม่ได้ แค่ log ไว้)
+func recordQuizRun(r *http.Request, run db.QuizRun) {
+	pid := requestPlayerID(r)
+	if pid == "" {
+		return
+	}
+	run.PlayerID = pid
+	if err := db.InsertQuizRun(r.Context(), run); err != nil {
+		log.Printf("recordQuizRun: %v", err)
+	}
+}
+
 // ==== (3) Handlers ====
 
 func GetQuiz(w http.ResponseWriter, r *http.Request) {
@@ -130,12 +157,20 @@ func GetQuiz(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "cannot generate id", http.StatusInternalServerError)
 		return
 	}
-	exp := now.Add(60 * time.Second).Unix()
+	// party เรียก endpoint นี้ผ่าน HTTP พร้อม mode=party
+	mode := config.ModeClassic
+	if r.URL.Query().Get("mode") == config.ModeParty {
+		mode = config.ModeParty
+	}
+	seconds := roundSeconds(r, mode, tier)
+	exp := now.Add(time.Duration(seconds) * time.Second).Unix()
 
 	secret := getSecret()
 	payload := q.Answer + "|" + id + "|" + strconv.FormatInt(exp, 10)
 	token := sign(secret, payload)
 
+	recordQuizRun(r, db.QuizRun{ID: id, QuizID: q.ID, Mode: mode, Tier: tier, Category: category, Seconds: seconds})
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store") // ← กัน cache
 
@@ -143,6 +178,7 @@ func GetQuiz(w http.ResponseWriter, r *http.Request) {
 		ID:        id,
 		Token:     token,
 		Exp:       exp,
+		Seconds:   seconds,
 		HintCount: 2,
 	}
 	_ = json.NewEncoder(w).Encode(resp)
@@ -175,6 +211,18 @@ func CheckQuiz(w http.ResponseWriter, r *http.Request) {
 	signGuess := sign(secret, normalizedGuess+"|"+req.ID+"|"+strconv.FormatInt(req.Exp, 10))
 	ok := equalHMAC(signGuess, req.Token)
 
+	if ok {
+		if pid := requestPlayerID(r); pid != "" {
+			solved, err := db.MarkQuizRunSolved(r.Context(), req.ID, pid)
+			if err != nil {
+				log.Printf("CheckQuiz: mark solved: %v", err)
+			}
+			if solved {
+				emitAchievement(achievements.Event{Kind: achievements.QuizSolved, PlayerID: pid})
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]bool{"correct": ok})
 }
@@ -245,26 +293,15 @@ func GetHint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	secret := getSecret()
-
-	// ดึง answer+hint1+hint2 ทั้งหมด (หรือทำฟังก์ชันหาเฉพาะที่ match ก็ได้)
-	rows, err := db.GetAllQuizLite(r.Context()) // ← ดูโค้ดส่วน db ด้านล่าง
-	if err != nil {
+	// หาโจทย์จาก token (ดู findQuizByToken ใน quiz_ask.go)
+	q, err := findQuizByToken(r.Context(), req.ID, req.Exp, req.Token)
+	if err != nil && !errors.Is(err, db.ErrNoQuiz) {
 		http.Error(w, "cannot get hint", http.StatusInternalServerError)
 		return
 	}
-
-	var hint string
-	for _, row := range rows {
-		signed := sign(secret, row.Answer+"|"+req.ID+"|"+strconv.FormatInt(req.Exp, 10))
-		if equalHMAC(signed, req.Token) {
-			if req.Index == 1 {
-				hint = row.Hint1
-			} else {
-				hint = row.Hint2
-			}
-			break
-		}
+	hint := q.Hint1
+	if req.Index == 2 {
+		hint = q.Hint2
 	}
 
 	if hint == "" {
@@ -272,6 +309,12 @@ func GetHint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if pid := requestPlayerID(r); pid != "" {
+		if err := db.RecordQuizRunHint(r.Context(), req.ID, pid, req.Index); err != nil {
+			log.Printf("GetHint: record hint: %v", err)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store")
 	_ = json.NewEncoder(w).Encode(map[string]any{
@@ -290,6 +333,7 @@ type MultipleChoiceQuizResp struct {
 	HintCount int    `json:"hintCount"`
 	Token     string `json:"token"`
 	Exp       int64  `json:"exp"`
+	Seconds   int    `json:"seconds"`
 }
 
 // GetQuizForMultipleChoice returns quiz data with answer for multiple-choice games
@@ -327,14 +371,17 @@ func GetQuizForMultipleChoice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	seconds := roundSeconds(r, config.ModeChoice, poolTierForLevel(level))
 	now := time.Now()
-	exp := now.Add(60 * time.Second).Unix()
+	exp := now.Add(time.Duration(seconds) * time.Second).Unix()
 
 	// Create token for this quiz
 	secret := getSecret()
 	tokenData := quiz.Answer + "|" + id + "|" + strconv.FormatInt(exp, 10)
 	token := sign(secret, tokenData)
 
+	recordQuizRun(r, db.QuizRun{ID: id, QuizID: quiz.ID, Mode: config.ModeChoice, Tier: quiz.Tier, Category: category, Seconds: seconds})
+
 	// Return quiz data with answer for multiple-choice games
 	resp := MultipleChoiceQuizResp{
 		ID:        id,
@@ -344,6 +391,7 @@ func GetQuizForMultipleChoice(w http.ResponseWriter, r *http.Request) {
 		HintCount: 2,
 		Token:     token,
 		Exp:       exp,
+		Seconds:   seconds,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
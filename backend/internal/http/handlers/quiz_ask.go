@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/llm"
+	"my-app-backend/internal/moderation"
+)
+
+const (
+	maxAskRunes     = 200 // คำถามถึงผู้ช่วยใบ้คำ
+	askOutputTokens = 120 // คำตอบแค่ ใช่/ไม่ใช่ + ใบ้สั้น ๆ
+)
+
+// ตอบแทนเมื่อคำตอบของโมเดลมีคำลับหลุดมา
+const askBlockedReply = "ตอบข้อนี้ไม่ได้ เดี๋ยวจะเป็นการเฉลย ลองถามแบบอื่นนะ"
+
+type AskReq struct {
+	ID       string `json:"id"`
+	Token    string `json:"token"`
+	Exp      int64  `json:"exp"`
+	Question string `json:"question"` // เช่น "ตัวใหญ่กว่าแมวไหม"
+}
+
+// POST /api/quiz/ask {id, token, exp, question} → {reply, blocked}
+// ผู้ช่วยใบ้คำ: backend หาคำตอบจาก token เอง (client ไม่เคยเห็น) แล้วสั่งให้โมเดลตอบแค่ใช่/ไม่ใช่หรือใบ้อ้อม ๆ
+// คำตอบของโมเดลผ่าน answerGuard ก่อนเสมอ (จึงไม่ stream) และทุกคำถามนับเป็นการใช้ hint
+func AskQuiz(w http.ResponseWriter, r *http.Request) {
+	if !checkChatRate(w, r, "quiz ask") {
+		return
+	}
+	var req AskReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	req.Question = strings.TrimSpace(req.Question)
+	if req.Question == "" {
+		writeError(w, http.StatusBadRequest, "question required")
+		return
+	}
+	if utf8.RuneCountInString(req.Question) > maxAskRunes {
+		writeError(w, http.StatusRequestEntityTooLarge, "question too long (max 200 characters)")
+		return
+	}
+	if time.Now().Unix() > req.Exp {
+		writeError(w, http.StatusGone, "quiz expired")
+		return
+	}
+	q, err := findQuizByToken(r.Context(), req.ID, req.Exp, req.Token)
+	if errors.Is(err, db.ErrNoQuiz) {
+		writeError(w, http.StatusNotFound, "quiz not found")
+		return
+	}
+	if err != nil {
+		log.Printf("AskQuiz: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load quiz")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), llm.Timeout())
+	defer cancel()
+	llmReq := llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: hintSystemPrompt(q)},
+			{Role: llm.RoleUser, Content: req.Question},
+		},
+		MaxOutputTokens: askOutputTokens,
+	}
+	resp, err := chatProvider(w, r).Complete(ctx, llmReq)
+	if err != nil {
+		writeLLMError(w, "quiz ask", err)
+		return
+	}
+	recordLLMUsage(llm.UseCaseHint, llmReq, resp)
+
+	// นับเมื่อโมเดลตอบแล้ว (ถูกบล็อกก็นับ — ถามไปแล้ว)
+	if pid := requestPlayerID(r); pid != "" {
+		if err := db.RecordQuizRunQuestion(r.Context(), req.ID, pid); err != nil {
+			log.Printf("AskQuiz: record question: %v", err)
+		}
+	}
+
+	reply := strings.TrimSpace(resp.Text)
+	blocked := newAnswerGuard(q).Leaks(reply)
+	if blocked {
+		log.Printf("AskQuiz: blocked reply for quiz run %s (provider %s)", req.ID, resp.Provider)
+		reply = askBlockedReply
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]any{"reply": reply, "blocked": blocked})
+}
+
+// hintSystemPrompt: prompt ของ use case hint + คำลับ (อยู่แค่ฝั่ง server กับ provider)
+func hintSystemPrompt(q db.QuizLite) string {
+	var b strings.Builder
+	b.WriteString(llm.SystemPromptFor(llm.UseCaseHint))
+	b.WriteString("\n\nคำลับ: ")
+	b.WriteString(q.Answer)
+	if len(q.Aliases) > 0 {
+		b.WriteString("\nคำที่ห้ามพูดด้วย: ")
+		b.WriteString(strings.Join(q.Aliases, ", "))
+	}
+	return b.String()
+}
+
+// findQuizByToken หาโจทย์ที่ token (HMAC ของ answer|id|exp) ชี้ถึง
+func findQuizByToken(ctx context.Context, id string, exp int64, token string) (db.QuizLite, error) {
+	rows, err := db.GetAllQuizLite(ctx)
+	if err != nil {
+		return db.QuizLite{}, err
+	}
+	secret := getSecret()
+	suffix := "|" + id + "|" + strconv.FormatInt(exp, 10)
+	for _, row := range rows {
+		if equalHMAC(sign(secret, row.Answer+suffix), token) {
+			return row, nil
+		}
+	}
+	return db.QuizLite{}, db.ErrNoQuiz
+}
+
+// answerGuard จับคำตอบและคำพ้องในข้อความ ใช้ตัวจับคำของ moderation
+// (กันเลี่ยงแบบ แ-ม-ว, แ ม ว, แมวววว) หลังพับวรรณยุกต์/ตัวอักษรที่มองไม่เห็นแล้ว
+// คำไทยจับแบบ substring: คำตอบสั้นอาจบล็อกเกินบ้าง ยอมได้ดีกว่าเฉลยหลุด
+type answerGuard struct {
+	words *moderation.WordList
+}
+
+func newAnswerGuard(q db.QuizLite) answerGuard {
+	lines := make([]string, 0, 1+len(q.Aliases))
+	for _, s := range append([]string{q.Answer}, q.Aliases...) {
+		// ตัด prefix/suffix ที่ WordList ถือเป็นไวยากรณ์ (# comment, = exact, * prefix, - ยกเว้น)
+		s = strings.Trim(foldThai(s), "#=*-")
+		if s == "" {
+			continue
+		}
+		if !strings.ContainsFunc(s, func(r rune) bool { return r >= utf8.RuneSelf }) {
+			s += "*" // คำอังกฤษจับแบบขึ้นต้นคำ ให้ได้ cats, elephants ด้วย
+		}
+		lines = append(lines, s)
+	}
+	return answerGuard{words: moderation.NewWordList(lines)}
+}
+
+func (g answerGuard) Leaks(text string) bool {
+	return len(g.words.Find(foldThai(text))) > 0
+}
+
+// foldThai ทำให้ข้อความเทียบกันได้: ตัด zero-width, รวม ํา เป็น ำ,
+// ตัดวรรณยุกต์และเครื่องหมายบนตัวอักษร (็ ่ ้ ๊ ๋ ์ ํ ๎) และเป็นตัวพิมพ์เล็ก
+func foldThai(s string) string {
+	s = strings.ReplaceAll(s, "\u0e4d\u0e32", "\u0e33") // ํ+า → ำ
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '\u0e47' && r <= '\u0e4e': // ็ ่ ้ ๊ ๋ ์ ํ ๎
+			return -1
+		case r == '\u200b' || r == '\u200c' || r == '\u200d' || r == '\u2060' || r == '\ufeff':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(s)))
+}
@@ -2,10 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"my-app-backend/internal/achievements"
+	"my-app-backend/internal/auth"
 	"my-app-backend/internal/db"
 )
 
@@ -13,9 +20,11 @@ type Score struct {
 	Name     string `json:"name"`
 	Score    int    `json:"score"`
 	GameName string `json:"gamename"`
+	Receipt  string `json:"receipt,omitempty"` // จำเป็นถ้าเกม requires_receipt (เช่น party ได้จาก game_over)
 }
 
-// POST /api/scores
+// POST /api/scores {name, score, gamename[, receipt]}
+// gamename ต้องอยู่ใน GET /api/games และ score ต้องอยู่ในช่วง min_score..max_score ของเกม
 func SaveScore(w http.ResponseWriter, r *http.Request) {
 	var s Score
 	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
@@ -25,54 +34,305 @@ func SaveScore(w http.ResponseWriter, r *http.Request) {
 	s.Name = strings.TrimSpace(s.Name)
 	s.GameName = strings.TrimSpace(s.GameName)
 
-	if s.Name == "" {
-		http.Error(w, "name required", http.StatusBadRequest)
-		return
-	}
 	if s.GameName == "" {
 		http.Error(w, "gamename required", http.StatusBadRequest)
 		return
 	}
-	if s.Score < 0 {
-		s.Score = 0
+	game, ok := lookupGame(w, r, s.GameName)
+	if !ok {
+		return
+	}
+	if s.Score < game.MinScore || s.Score > game.MaxScore {
+		writeError(w, http.StatusUnprocessableEntity,
+			fmt.Sprintf("score out of range (%d..%d)", game.MinScore, game.MaxScore))
+		return
+	}
+	// มี X-Player-Token → บันทึกในชื่อของ player นั้น
+	playerID, name, ok := claimName(w, r, s.Name)
+	if !ok {
+		return
 	}
-	if s.Score > 1_000_000 {
-		s.Score = 1_000_000
+	s.Name = name
+	// receipt ออกให้ชื่อที่แสดงในเกม จึงตรวจหลังได้ชื่อสุดท้ายแล้ว
+	var receipt *db.ScoreReceipt
+	if game.RequiresReceipt {
+		if s.Receipt == "" {
+			writeError(w, http.StatusUnprocessableEntity, "score receipt required for this game")
+			return
+		}
+		nonce, exp, err := auth.VerifyScoreReceipt(s.Receipt, game.ID, s.Name, s.Score, time.Now())
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "invalid score receipt")
+			return
+		}
+		receipt = &db.ScoreReceipt{Nonce: nonce, ExpiresAt: exp}
 	}
 
-	if err := db.InsertScore(r.Context(), s.Name, s.Score, s.GameName); err != nil {
+	err := db.InsertScore(r.Context(), s.Name, s.Score, s.GameName, playerID, receipt)
+	if errors.Is(err, db.ErrReceiptUsed) {
+		writeError(w, http.StatusUnprocessableEntity, "invalid score receipt")
+		return
+	}
+	if err != nil {
 		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	emitAchievement(achievements.Event{
+		Kind: achievements.ScoreSaved, PlayerID: playerID, Name: s.Name,
+		Game: game.ID, ScoreOrder: game.ScoreOrder, Score: s.Score,
+	})
+	emitTopScore(game, s.Name, s.Score, playerID)
 	w.WriteHeader(http.StatusOK)
 }
 
-// GET /api/scores?limit=10&gamename=PolaJigsaw
+// GET /api/scores?limit=10&gamename=PolaJigsaw[&season=current|all|<id>][&period=daily|weekly|monthly|all][&mode=best][&cursor=...]
+//
+// season ไม่ส่ง = season ปัจจุบัน (all = ทุก season รวมกัน)
+// period ช่วงเวลาตามปฏิทิน Asia/Bangkok ภายใน season (ไม่ส่ง = ทั้ง season)
+// mode=best = หนึ่งแถวต่อผู้เล่น (คะแนนสูงสุด, เท่ากันเอาอันที่ทำได้ก่อน)
+// cursor = next_cursor/prev_cursor จาก response ก่อนหน้า
 func GetScores(w http.ResponseWriter, r *http.Request) {
-	limit := 10
-	if v := r.URL.Query().Get("limit"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
-			limit = n
+	limit, cur, err := pageParams(r, 10)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	game := strings.TrimSpace(r.URL.Query().Get("gamename")) // "" = ทุกเกม (เรียงมากก่อน)
+	order := db.OrderDesc
+	if game != "" {
+		g, ok := lookupGame(w, r, game)
+		if !ok {
+			return
 		}
+		order = g.ScoreOrder
+	}
+
+	period, ok := parsePeriod(r.URL.Query().Get("period"))
+	if !ok {
+		http.Error(w, "invalid period", http.StatusBadRequest)
+		return
+	}
+	season, ok := requestSeason(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	start, reset := periodBounds(period, now)
+	rng := db.TimeRange{From: start, To: reset}
+	var seasonOut *seasonResp
+	if season != nil {
+		rng.Season = season.ID
+		sr := toSeasonResp(*season, now)
+		seasonOut = &sr
 	}
-	game := strings.TrimSpace(r.URL.Query().Get("gamename")) // "" = ทุกเกม
 
-	rows, err := db.GetTopScores(r.Context(), game, limit)
+	var rows []db.ScoreRow
+	switch r.URL.Query().Get("mode") {
+	case "", "all":
+		rows, err = db.GetTopScores(r.Context(), game, order, rng, cur, limit+1)
+	case "best":
+		rows, err = db.GetBestScores(r.Context(), game, order, rng, cur, limit+1)
+	default:
+		http.Error(w, "invalid mode", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	type outScore struct {
-		Name     string `json:"name"`
-		Score    int    `json:"score"`
-		GameName string `json:"gamename"`
-	}
+	rows, next, prev := paginate(rows, limit, cur, func(s db.ScoreRow) db.Cursor {
+		return db.Cursor{Score: s.Score, CreatedAt: s.CreatedAt, ID: s.ID}
+	})
 	out := make([]outScore, 0, len(rows))
 	for _, row := range rows {
 		out = append(out, outScore{Name: row.Name, Score: row.Score, GameName: row.GameName})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(out)
+	_ = json.NewEncoder(w).Encode(leaderboardResp{
+		Season:     seasonOut,
+		Period:     period,
+		Timezone:   bangkok.String(),
+		StartsAt:   formatTime(start),
+		ResetsAt:   formatTime(reset),
+		Items:      out,
+		NextCursor: next,
+		PrevCursor: prev,
+	})
+}
+
+type outScore struct {
+	Name     string `json:"name"`
+	Score    int    `json:"score"`
+	GameName string `json:"gamename"`
+}
+
+type leaderboardResp struct {
+	Season     *seasonResp `json:"season,omitempty"` // nil = ทุก season
+	Period     string      `json:"period"`
+	Timezone   string      `json:"timezone"`
+	StartsAt   string      `json:"starts_at,omitempty"`
+	ResetsAt   string      `json:"resets_at,omitempty"` // ว่างสำหรับ all-time
+	Items      []outScore  `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+}
+
+// formatTime: zero time → "" (ใช้คู่กับ omitempty)
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(timeLayout)
+}
+
+type rankEntry struct {
+	Rank  int    `json:"rank"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// GET /api/scores/rank?gamename=PolaJigsaw&name=Pola[&season=current|all|<id>][&period=weekly][&k=3]
+// อันดับของผู้เล่น (ใช้คะแนนที่ดีที่สุดของแต่ละคน) พร้อมคนที่อยู่เหนือ/ใต้ k อันดับ
+func GetScoreRank(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	game := strings.TrimSpace(q.Get("gamename"))
+	name := strings.TrimSpace(q.Get("name"))
+	if game == "" {
+		http.Error(w, "gamename required", http.StatusBadRequest)
+		return
+	}
+	if name == "" {
+		http.Error(w, "name required", http.StatusBadRequest)
+		return
+	}
+	period, ok := parsePeriod(q.Get("period"))
+	if !ok {
+		http.Error(w, "invalid period", http.StatusBadRequest)
+		return
+	}
+	k := 3
+	if v := q.Get("k"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 25 {
+			k = n
+		}
+	}
+	g, ok := lookupGame(w, r, game)
+	if !ok {
+		return
+	}
+	season, ok := requestSeason(w, r)
+	if !ok {
+		return
+	}
+	start, reset := periodBounds(period, time.Now())
+	rng := db.TimeRange{From: start, To: reset}
+	if season != nil {
+		rng.Season = season.ID
+	}
+
+	rows, err := db.GetScoreRank(r.Context(), game, g.ScoreOrder, name, rng, k)
+	if errors.Is(err, db.ErrNoScore) {
+		writeError(w, http.StatusNotFound, "no score for player in this period")
+		return
+	}
+	if err != nil {
+		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var me db.RankedScore
+	for _, row := range rows {
+		if row.Name == name {
+			me = row
+			break
+		}
+	}
+	above := []rankEntry{}
+	below := []rankEntry{}
+	for _, row := range rows {
+		e := rankEntry{Rank: row.Rank, Name: row.Name, Score: row.Score}
+		switch {
+		case row.Position < me.Position:
+			above = append(above, e)
+		case row.Position > me.Position:
+			below = append(below, e)
+		}
+	}
+
+	var seasonID int64
+	if season != nil {
+		seasonID = season.ID
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"gamename":   game,
+		"season_id":  seasonID, // 0 = ทุก season
+		"period":     period,
+		"timezone":   bangkok.String(),
+		"resets_at":  formatTime(reset),
+		"name":       me.Name,
+		"score":      me.Score,
+		"rank":       me.Rank,
+		"total":      me.Total,
+		"percentile": math.Round(me.Percentile*10) / 10,
+		"above":      above,
+		"below":      below,
+	})
+}
+
+// GET /api/scores/history?player_id=<id>|me[&gamename=PolaJigsaw][&limit=50]
+// GET /api/scores/history?name=Pola  — เฉพาะคะแนนที่ไม่ผูกกับ player (ไม่ระบุตัวตน/ก่อนมีระบบ player)
+// ประวัติคะแนนทุกครั้งของผู้เล่น (ใหม่สุดก่อน)
+func GetScoreHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := strings.TrimSpace(q.Get("name"))
+	playerID := strings.TrimSpace(q.Get("player_id"))
+	if playerID == "me" {
+		if playerID = requestPlayerID(r); playerID == "" {
+			writeError(w, http.StatusUnauthorized, "player token required")
+			return
+		}
+	}
+	if playerID != "" {
+		p, err := db.LookupPlayer(r.Context(), playerID)
+		if errors.Is(err, db.ErrNoPlayer) {
+			writeError(w, http.StatusNotFound, "player not found")
+			return
+		}
+		if err != nil {
+			log.Printf("GetScoreHistory: %v", err)
+			writeError(w, http.StatusInternalServerError, "cannot load player")
+			return
+		}
+		name = p.DisplayName // คะแนนเก่าที่ไม่มี player_id ใช้ชื่อนี้
+	}
+	if name == "" {
+		http.Error(w, "player_id or name required", http.StatusBadRequest)
+		return
+	}
+	game := strings.TrimSpace(q.Get("gamename"))
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+
+	rows, err := db.GetPlayerScores(r.Context(), game, playerID, name, limit)
+	if err != nil {
+		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type historyRow struct {
+		Score     int    `json:"score"`
+		GameName  string `json:"gamename"`
+		CreatedAt string `json:"created_at"`
+	}
+	out := make([]historyRow, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, historyRow{Score: row.Score, GameName: row.GameName, CreatedAt: row.CreatedAt.Format(timeLayout)})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"player_id": playerID, "name": name, "scores": out})
 }
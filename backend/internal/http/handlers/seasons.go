@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/db"
+)
+
+type seasonResp struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+	Status   string `json:"status"` // upcoming | current | ended | archived
+}
+
+func toSeasonResp(s db.Season, now time.Time) seasonResp {
+	status := "current"
+	switch {
+	case s.ArchivedAt != nil:
+		status = "archived"
+	case now.Before(s.StartsAt):
+		status = "upcoming"
+	case !now.Before(s.EndsAt):
+		status = "ended"
+	}
+	return seasonResp{
+		ID:       s.ID,
+		Name:     s.Name,
+		StartsAt: s.StartsAt.Format(timeLayout),
+		EndsAt:   s.EndsAt.Format(timeLayout),
+		Status:   status,
+	}
+}
+
+// requestSeason อ่าน ?season=current|all|<id> (ไม่ส่ง = current)
+// คืน nil = ไม่จำกัด season (all หรือยังไม่มี season ปัจจุบัน)
+// ok=false แปลว่าเขียน error response ไปแล้ว
+func requestSeason(w http.ResponseWriter, r *http.Request) (*db.Season, bool) {
+	v := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("season")))
+	var (
+		s   db.Season
+		err error
+	)
+	switch v {
+	case "all":
+		return nil, true
+	case "", "current":
+		s, err = db.CurrentSeason(r.Context())
+		if errors.Is(err, db.ErrNoSeason) {
+			return nil, true
+		}
+	default:
+		id, perr := strconv.ParseInt(v, 10, 64)
+		if perr != nil || id <= 0 {
+			http.Error(w, "invalid season", http.StatusBadRequest)
+			return nil, false
+		}
+		s, err = db.GetSeason(r.Context(), id)
+		if errors.Is(err, db.ErrNoSeason) {
+			writeError(w, http.StatusNotFound, "season not found")
+			return nil, false
+		}
+	}
+	if err != nil {
+		log.Printf("requestSeason: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load season")
+		return nil, false
+	}
+	return &s, true
+}
+
+// GET /api/seasons — ใหม่สุดก่อน
+func ListSeasons(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.ListSeasons(r.Context())
+	if err != nil {
+		log.Printf("ListSeasons: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load seasons")
+		return
+	}
+	now := time.Now()
+	out := make([]seasonResp, 0, len(rows))
+	for _, s := range rows {
+		out = append(out, toSeasonResp(s, now))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+type standingResp struct {
+	Rank       int    `json:"rank"`
+	Name       string `json:"name"`
+	Score      int    `json:"score"`
+	AchievedAt string `json:"achieved_at"`
+}
+
+type standingsResp struct {
+	Season   seasonResp `json:"season"`
+	Gamename string     `json:"gamename"`
+	pageResp[standingResp]
+}
+
+// GET /api/seasons/{id}/standings?gamename=PolaJigsaw[&limit=10][&cursor=...]
+// อันดับสุดท้ายของ season ที่จบและ archive แล้ว (season ปัจจุบันดูที่ /api/scores)
+func GetSeasonStandings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid season", http.StatusBadRequest)
+		return
+	}
+	game := strings.TrimSpace(r.URL.Query().Get("gamename"))
+	if game == "" {
+		http.Error(w, "gamename required", http.StatusBadRequest)
+		return
+	}
+	limit, cur, err := pageParams(r, 10)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, err := db.GetSeason(r.Context(), id)
+	if errors.Is(err, db.ErrNoSeason) {
+		writeError(w, http.StatusNotFound, "season not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetSeasonStandings: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load season")
+		return
+	}
+	if s.ArchivedAt == nil {
+		writeError(w, http.StatusConflict, "season not archived yet (use /api/scores?season=<id>)")
+		return
+	}
+
+	rows, err := db.GetSeasonStandings(r.Context(), id, game, cur, limit+1)
+	if err != nil {
+		log.Printf("GetSeasonStandings: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load standings")
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(s db.Standing) db.Cursor {
+		return db.Cursor{ID: int64(s.Position)}
+	})
+	out := make([]standingResp, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, standingResp{Rank: row.Rank, Name: row.Name, Score: row.Score, AchievedAt: row.AchievedAt.Format(timeLayout)})
+	}
+	writeJSON(w, http.StatusOK, standingsResp{
+		Season:   toSeasonResp(s, time.Now()),
+		Gamename: game,
+		pageResp: pageResp[standingResp]{Items: out, NextCursor: next, PrevCursor: prev},
+	})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/achievements"
+	"my-app-backend/internal/db"
+)
+
+// สถิติรวมทั้งหมดใช้หลาย query — เก็บไว้ในหน่วยความจำ PLAYER_STATS_TTL (default 60s)
+var (
+	statsMu    sync.Mutex
+	statsCache = map[string]cachedStats{}
+)
+
+type cachedStats struct {
+	stats db.PlayerStats
+	at    time.Time
+}
+
+func statsTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("PLAYER_STATS_TTL")); err == nil && d >= 0 {
+		return d
+	}
+	return time.Minute
+}
+
+func loadPlayerStats(r *http.Request, playerID string) (db.PlayerStats, time.Time, error) {
+	now := time.Now()
+	ttl := statsTTL()
+	statsMu.Lock()
+	c, ok := statsCache[playerID]
+	statsMu.Unlock()
+	if ok && now.Sub(c.at) < ttl {
+		return c.stats, c.at, nil
+	}
+
+	st, err := db.GetPlayerStats(r.Context(), playerID, achievements.Timezone)
+	if err != nil {
+		return st, now, err
+	}
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	for id, c := range statsCache {
+		if now.Sub(c.at) >= ttl {
+			delete(statsCache, id)
+		}
+	}
+	statsCache[playerID] = cachedStats{stats: st, at: now}
+	return st, now, nil
+}
+
+type gameStatsResp struct {
+	GameName   string  `json:"gamename"`
+	Played     int     `json:"played"`
+	Best       int     `json:"best"`
+	Average    float64 `json:"average"`
+	LastPlayed string  `json:"last_played"`
+}
+
+// GET /api/players/{id}/stats ("me" = ผู้เล่นของ token)
+func GetPlayerStats(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "me" {
+		if id = requestPlayerID(r); id == "" {
+			writeError(w, http.StatusUnauthorized, "player token required")
+			return
+		}
+	}
+	p, err := db.LookupPlayer(r.Context(), id)
+	if errors.Is(err, db.ErrNoPlayer) {
+		writeError(w, http.StatusNotFound, "player not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetPlayerStats: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load player")
+		return
+	}
+
+	st, at, err := loadPlayerStats(r, id)
+	if err != nil {
+		log.Printf("GetPlayerStats: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot load stats")
+		return
+	}
+
+	games := make([]gameStatsResp, 0, len(st.Games))
+	for _, g := range st.Games {
+		games = append(games, gameStatsResp{
+			GameName:   g.GameName,
+			Played:     g.Played,
+			Best:       g.Best,
+			Average:    math.Round(g.Average*100) / 100,
+			LastPlayed: g.LastPlayed.Format(timeLayout),
+		})
+	}
+	hintsPerWord := 0.0
+	if st.WordsPlayed > 0 {
+		hintsPerWord = math.Round(float64(st.HintsUsed)/float64(st.WordsPlayed)*100) / 100
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"player": toPlayerResp(p),
+		"games":  games,
+		"party": map[string]int{
+			"played": st.PartyPlayed,
+			"won":    st.PartyWon,
+		},
+		"words": map[string]int{
+			"played":          st.WordsPlayed,
+			"solved":          st.WordsSolved,
+			"solved_no_hints": st.SolvedNoHints,
+		},
+		"hints": map[string]any{
+			"used":     st.HintsUsed,
+			"per_word": hintsPerWord,
+		},
+		"favorite_category": st.FavoriteCategory,
+		"streak": map[string]any{
+			"current":  st.CurrentStreak,
+			"longest":  st.LongestStreak,
+			"timezone": achievements.Timezone,
+		},
+		"computed_at": at.Format(timeLayout),
+	})
+}
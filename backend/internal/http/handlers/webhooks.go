@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+	"my-app-backend/internal/webhooks"
+)
+
+// emitWebhook เข้าคิว event แบบไม่ให้ request รอ (เหมือน emitAchievement)
+func emitWebhook(event string, data any, playerID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := webhooks.Emit(ctx, event, data, playerID); err != nil {
+			log.Printf("webhook %s: %v", event, err)
+		}
+	}()
+}
+
+// emitTopScore ส่ง score.top ถ้าคะแนนที่เพิ่งบันทึกขึ้นอันดับ 1 ของเกมใน season ปัจจุบัน
+// (อันดับ 1 ต้องเป็นคะแนนนี้ และอันดับ 2 ต้องแย่กว่า — เสมอกับของเดิมไม่นับ)
+func emitTopScore(game db.Game, name string, score int, playerID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var rng db.TimeRange
+		season, err := db.CurrentSeason(ctx)
+		if err == nil {
+			rng.Season = season.ID
+		} else if !errors.Is(err, db.ErrNoSeason) {
+			log.Printf("webhook %s: %v", webhooks.EventScoreTop, err)
+			return
+		}
+		top, err := db.GetTopScores(ctx, game.ID, game.ScoreOrder, rng, nil, 2)
+		if err != nil {
+			log.Printf("webhook %s: %v", webhooks.EventScoreTop, err)
+			return
+		}
+		if len(top) == 0 || top[0].Name != name || top[0].Score != score || (len(top) > 1 && top[1].Score == score) {
+			return
+		}
+		data := map[string]any{
+			"game":         game.ID,
+			"game_name":    game.DisplayName,
+			"name":         name,
+			"score":        score,
+			"score_order":  game.ScoreOrder,
+			"season_id":    rng.Season,
+			"achieved_at":  top[0].CreatedAt.UTC().Format(time.RFC3339),
+			"previous_top": nil,
+		}
+		if len(top) > 1 {
+			data["previous_top"] = map[string]any{"name": top[1].Name, "score": top[1].Score}
+		}
+		if _, err := webhooks.Emit(ctx, webhooks.EventScoreTop, data, playerID); err != nil {
+			log.Printf("webhook %s: %v", webhooks.EventScoreTop, err)
+		}
+	}()
+}
+
+// ===== Admin: /api/admin/webhooks =====
+
+type webhookEndpointResp struct {
+	ID          int64    `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"` // เต็มเฉพาะตอนสร้าง/หมุน secret
+	SecretHint  string   `json:"secret_hint"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+func toWebhookEndpointResp(e db.WebhookEndpoint, showSecret bool) webhookEndpointResp {
+	out := webhookEndpointResp{
+		ID:          e.ID,
+		URL:         e.URL,
+		Events:      e.Events,
+		Description: e.Description,
+		Active:      e.Active,
+		SecretHint:  "…" + e.Secret[max(len(e.Secret)-4, 0):],
+		CreatedAt:   e.CreatedAt.Format(timeLayout),
+		UpdatedAt:   e.UpdatedAt.Format(timeLayout),
+	}
+	if out.Events == nil {
+		out.Events = []string{}
+	}
+	if showSecret {
+		out.Secret = e.Secret
+	}
+	return out
+}
+
+func newWebhookSecret() (string, error) {
+	id, err := auth.NewID()
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + id, nil
+}
+
+// validWebhookURL: http(s) ที่มี host เท่านั้น (ตั้งได้เฉพาะ admin — http ไว้ทดสอบกับ server ในเครื่อง)
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil
+}
+
+// normalizeWebhookEvents: ไม่ซ้ำ และต้องเป็น event ที่มีจริง หรือ "*"
+func normalizeWebhookEvents(in []string) ([]string, error) {
+	out := make([]string, 0, len(in))
+	for _, e := range in {
+		e = strings.TrimSpace(e)
+		if e == "" || slices.Contains(out, e) {
+			continue
+		}
+		if e != "*" && !slices.Contains(webhooks.Events, e) {
+			return nil, errors.New("unknown event: " + e + " (known: " + strings.Join(webhooks.Events, ", ") + ", *)")
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func webhookIDParam(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeWebhookError(w http.ResponseWriter, where string, err error) {
+	switch {
+	case errors.Is(err, db.ErrNoWebhook):
+		writeError(w, http.StatusNotFound, "webhook not found")
+	case errors.Is(err, db.ErrNoWebhookDelivery):
+		writeError(w, http.StatusNotFound, "delivery not found")
+	default:
+		log.Printf("%s: %v", where, err)
+		writeError(w, http.StatusInternalServerError, "webhook storage error")
+	}
+}
+
+// GET /api/admin/webhooks
+func AdminListWebhooks(w http.ResponseWriter, r *http.Request) {
+	eps, err := db.ListWebhookEndpoints(r.Context())
+	if err != nil {
+		writeWebhookError(w, "AdminListWebhooks", err)
+		return
+	}
+	out := make([]webhookEndpointResp, 0, len(eps))
+	for _, e := range eps {
+		out = append(out, toWebhookEndpointResp(e, false))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out, "events": webhooks.Events})
+}
+
+// POST /api/admin/webhooks {url, events, description?, active?}
+// secret สร้างให้และแสดงครั้งเดียวใน response นี้
+func AdminCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		URL         string   `json:"url"`
+		Events      []string `json:"events"`
+		Description string   `json:"description"`
+		Active      *bool    `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	in.URL = strings.TrimSpace(in.URL)
+	if !validWebhookURL(in.URL) {
+		writeError(w, http.StatusBadRequest, "url must be an http(s) URL")
+		return
+	}
+	events, err := normalizeWebhookEvents(in.Events)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot generate secret")
+		return
+	}
+	e, err := db.CreateWebhookEndpoint(r.Context(), db.WebhookEndpoint{
+		URL:         in.URL,
+		Secret:      secret,
+		Events:      events,
+		Description: truncate(strings.TrimSpace(in.Description), 200),
+		Active:      in.Active == nil || *in.Active,
+	})
+	if err != nil {
+		writeWebhookError(w, "AdminCreateWebhook", err)
+		return
+	}
+	log.Printf("webhook %d created by %s: %s %v", e.ID, requestAdmin(r), e.URL, e.Events)
+	writeJSON(w, http.StatusCreated, toWebhookEndpointResp(e, true))
+}
+
+// GET /api/admin/webhooks/{id}
+func AdminGetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	e, err := db.GetWebhookEndpoint(r.Context(), id)
+	if err != nil {
+		writeWebhookError(w, "AdminGetWebhook", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toWebhookEndpointResp(e, false))
+}
+
+// PATCH /api/admin/webhooks/{id} {url?, events?, description?, active?, rotate_secret?}
+// active=false: delivery ที่ค้างในคิวของ endpoint นี้เป็น failed ทันที (retry ทีละรายการได้)
+func AdminUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var in struct {
+		URL          *string   `json:"url"`
+		Events       *[]string `json:"events"`
+		Description  *string   `json:"description"`
+		Active       *bool     `json:"active"`
+		RotateSecret bool      `json:"rotate_secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	p := db.WebhookEndpointPatch{Active: in.Active}
+	if in.URL != nil {
+		u := strings.TrimSpace(*in.URL)
+		if !validWebhookURL(u) {
+			writeError(w, http.StatusBadRequest, "url must be an http(s) URL")
+			return
+		}
+		p.URL = &u
+	}
+	if in.Events != nil {
+		events, err := normalizeWebhookEvents(*in.Events)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		p.Events = &events
+	}
+	if in.Description != nil {
+		d := truncate(strings.TrimSpace(*in.Description), 200)
+		p.Description = &d
+	}
+	if in.RotateSecret {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "cannot generate secret")
+			return
+		}
+		p.Secret = &secret
+	}
+	e, err := db.UpdateWebhookEndpoint(r.Context(), id, p)
+	if err != nil {
+		writeWebhookError(w, "AdminUpdateWebhook", err)
+		return
+	}
+	log.Printf("webhook %d updated by %s", e.ID, requestAdmin(r))
+	writeJSON(w, http.StatusOK, toWebhookEndpointResp(e, in.RotateSecret))
+}
+
+// DELETE /api/admin/webhooks/{id} — ลบพร้อมคิวและ log
+func AdminDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := db.DeleteWebhookEndpoint(r.Context(), id); err != nil {
+		writeWebhookError(w, "AdminDeleteWebhook", err)
+		return
+	}
+	log.Printf("webhook %d deleted by %s", id, requestAdmin(r))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/admin/webhooks/{id}/ping — เข้าคิว event "ping" ให้ endpoint นี้ (แม้ปิดอยู่)
+func AdminPingWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	n, err := webhooks.Ping(r.Context(), id)
+	if err != nil {
+		writeWebhookError(w, "AdminPingWebhook", err)
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"ok": true})
+}
+
+type webhookDeliveryResp struct {
+	ID            int64           `json:"id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt string          `json:"next_attempt_at,omitempty"`
+	LastStatus    int             `json:"last_status"`
+	LastError     string          `json:"last_error"`
+	CreatedAt     string          `json:"created_at"`
+	UpdatedAt     string          `json:"updated_at"`
+}
+
+func toWebhookDeliveryResp(d db.WebhookDelivery) webhookDeliveryResp {
+	out := webhookDeliveryResp{
+		ID: d.ID, Event: d.Event, Payload: d.Payload, Status: d.Status, Attempts: d.Attempts,
+		LastStatus: d.LastStatus, LastError: d.LastError,
+		CreatedAt: d.CreatedAt.Format(timeLayout), UpdatedAt: d.UpdatedAt.Format(timeLayout),
+	}
+	if d.Status == db.DeliveryPending {
+		out.NextAttemptAt = d.NextAttemptAt.Format(timeLayout)
+	}
+	return out
+}
+
+// GET /api/admin/webhooks/{id}/deliveries[?status=pending|delivered|failed][&limit=20][&cursor=...]
+func AdminListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && status != db.DeliveryPending && status != db.DeliveryDelivered && status != db.DeliveryFailed {
+		writeError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+	limit, cur, err := pageParams(r, 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := db.ListWebhookDeliveries(r.Context(), id, status, cur, limit+1)
+	if err != nil {
+		writeWebhookError(w, "AdminListWebhookDeliveries", err)
+		return
+	}
+	rows, next, prev := paginate(rows, limit, cur, func(d db.WebhookDelivery) db.Cursor {
+		return db.Cursor{CreatedAt: d.CreatedAt, ID: d.ID}
+	})
+	out := make([]webhookDeliveryResp, 0, len(rows))
+	for _, d := range rows {
+		out = append(out, toWebhookDeliveryResp(d))
+	}
+	writeJSON(w, http.StatusOK, pageResp[webhookDeliveryResp]{Items: out, NextCursor: next, PrevCursor: prev})
+}
+
+// GET /api/admin/webhooks/{id}/deliveries/{did} — พร้อม log การส่งทุกครั้ง
+func AdminGetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	did, ok := webhookIDParam(w, r, "did")
+	if !ok {
+		return
+	}
+	d, err := db.GetWebhookDelivery(r.Context(), id, did)
+	if err != nil {
+		writeWebhookError(w, "AdminGetWebhookDelivery", err)
+		return
+	}
+	attempts, err := db.ListWebhookAttempts(r.Context(), did)
+	if err != nil {
+		writeWebhookError(w, "AdminGetWebhookDelivery", err)
+		return
+	}
+	type attemptResp struct {
+		Attempt    int    `json:"attempt"`
+		StatusCode int    `json:"status_code"`
+		Error      string `json:"error"`
+		DurationMS int64  `json:"duration_ms"`
+		CreatedAt  string `json:"created_at"`
+	}
+	outAttempts := make([]attemptResp, 0, len(attempts))
+	for _, a := range attempts {
+		outAttempts = append(outAttempts, attemptResp{
+			Attempt: a.Attempt, StatusCode: a.StatusCode, Error: a.Error,
+			DurationMS: a.Duration.Milliseconds(), CreatedAt: a.CreatedAt.Format(timeLayout),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"delivery": toWebhookDeliveryResp(d),
+		"attempts": outAttempts,
+	})
+}
+
+// POST /api/admin/webhooks/{id}/deliveries/{did}/retry — ส่งใหม่รอบถัดไปของ worker
+func AdminRetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	did, ok := webhookIDParam(w, r, "did")
+	if !ok {
+		return
+	}
+	d, err := db.RetryWebhookDelivery(r.Context(), id, did)
+	if err != nil {
+		writeWebhookError(w, "AdminRetryWebhookDelivery", err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, toWebhookDeliveryResp(d))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"my-app-backend/internal/http/handlers"
+	appmw "my-app-backend/internal/middleware"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -16,10 +17,13 @@ func NewRouter() *chi.Mux {
 	r := chi.NewRouter()
 
 	// ---------- Base middlewares ----------
-	r.Use(middleware.RequestID)                 // ใส่ X-Request-ID ให้ตามรอยง่าย
-	r.Use(middleware.RealIP)                    // ดึง IP จริงหลัง CDN/Proxy
-	r.Use(middleware.Recoverer)                 // กันแอปล้มจาก panic
-	r.Use(middleware.Timeout(15 * time.Second)) // กันแฮงค์ (รวมทั้ง preflight/options)
+	r.Use(middleware.RequestID) // ใส่ X-Request-ID ให้ตามรอยง่าย
+	r.Use(appmw.EchoRequestID)  // ส่ง X-Request-ID กลับให้ client
+	r.Use(appmw.RealIP)         // ดึง IP จริงหลัง CDN/Proxy (เชื่อ header เฉพาะจาก TRUSTED_PROXIES)
+	r.Use(middleware.Logger)    // log ทุก request พร้อม request id (เทียบกับ feedback ได้)
+	r.Use(middleware.Recoverer) // กันแอปล้มจาก panic
+	// กันแฮงค์ (รวมทั้ง preflight/options) — ยกเว้น SSE ที่คุมเวลาเอง (llm.StreamTimeout)
+	r.Use(appmw.TimeoutExcept(15*time.Second, "/api/chat/stream"))
 
 	// ---------- CORS ----------
 	// อนุญาต FE หลัก + localhost ระหว่าง dev
@@ -29,7 +33,7 @@ func NewRouter() *chi.Mux {
 	}
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins: []string{fe, "http://localhost:5173", "http://localhost:3000"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		// ใช้ * เพื่อเลี่ยงปัญหา header แปลก ๆ จากเบราว์เซอร์/axios ใน preflight
 		AllowedHeaders:   []string{"*"},
 		ExposedHeaders:   []string{"*"},
@@ -37,6 +41,11 @@ func NewRouter() *chi.Mux {
 		MaxAge:           300,
 	}))
 
+	// ---------- Auth ----------
+	// แนบผู้เล่นที่ยืนยันตัวตนแล้วไว้ใน context (ไม่มี token = ไม่ระบุตัวตน)
+	// token เสีย/หมดอายุ = 401 ยกเว้น /health และ /api/auth/* ที่ผ่านไปแบบไม่ระบุตัวตน
+	r.Use(appmw.Authenticate)
+
 	// ---------- Health ----------
 	// Render จะเรียกเช็กเป็นระยะ และคุณก็ใช้ดูสถานะได้
 	r.Get("/health", handlers.Health)
@@ -49,13 +58,69 @@ func NewRouter() *chi.Mux {
 	r.Post("/api/quiz/reveal", handlers.RevealQuiz)
 	r.Post("/api/quiz/check", handlers.CheckQuiz)
 	r.Post("/api/quiz/hint", handlers.GetHint)
+	r.Post("/api/quiz/ask", handlers.AskQuiz) // ผู้ช่วยใบ้คำ (AI) ไม่เฉลยคำตอบ
+
+	r.Get("/api/games", handlers.ListGames)
 
 	r.Post("/api/scores", handlers.SaveScore)
 	r.Get("/api/scores", handlers.GetScores)
+	r.Get("/api/scores/rank", handlers.GetScoreRank)
+	r.Get("/api/scores/history", handlers.GetScoreHistory)
+
+	r.Get("/api/seasons", handlers.ListSeasons)
+	r.Get("/api/seasons/{id}/standings", handlers.GetSeasonStandings)
 
 	r.Post("/api/chat", handlers.ChatHandler)
+	r.Post("/api/chat/stream", handlers.ChatStreamHandler) // SSE: delta / done / error
+	r.Get("/api/chat/conversations", handlers.ListChatConversations)
+	r.Get("/api/chat/conversations/{id}", handlers.GetChatConversation)
+	r.Delete("/api/chat/conversations/{id}", handlers.DeleteChatConversation)
 	r.Post("/api/feedback", handlers.SaveFeedback)
 	r.Get("/api/feedback", handlers.GetFeedbacks)
+	r.Get("/api/feedback/form", handlers.GetFeedbackForm)
+
+	// ---------- Accounts ----------
+	r.Post("/api/auth/register", handlers.Register)
+	r.Post("/api/auth/login", handlers.Login)
+	r.Post("/api/auth/refresh", handlers.RefreshSession)
+	r.Post("/api/auth/logout", handlers.Logout)
+
+	// ---------- Players ----------
+	r.Post("/api/players/guest", handlers.CreateGuestPlayer)
+	r.Get("/api/players/me", handlers.GetMe)
+	r.Post("/api/players/merge", handlers.MergeGuest)
+	r.Get("/api/players/{id}/badges", handlers.GetPlayerBadges)
+	r.Get("/api/players/{id}/stats", handlers.GetPlayerStats)
+	r.Get("/api/players/{id}/export", handlers.ExportPlayerData)
+	r.Delete("/api/players/{id}", handlers.ErasePlayerData)
+
+	// ---------- Achievements ----------
+	r.Get("/api/achievements", handlers.ListAchievements)
+
+	// ---------- Admin (X-Admin-Key) ----------
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(appmw.RequireAdmin)
+		r.Get("/feedback", handlers.AdminListFeedback)
+		r.Get("/feedback/search", handlers.AdminSearchFeedback)
+		r.Get("/feedback/rejections", handlers.AdminListFeedbackRejections)
+		r.Get("/feedback/{id}", handlers.AdminGetFeedback)
+		r.Patch("/feedback/{id}", handlers.AdminUpdateFeedback)
+		r.Post("/feedback/{id}/notes", handlers.AdminAddFeedbackNote)
+		r.Get("/feedback/{id}/attachments/{aid}", handlers.AdminGetFeedbackAttachment)
+		r.Get("/feedback/{id}/attachments/{aid}/thumb", handlers.AdminGetFeedbackAttachmentThumb)
+
+		r.Get("/webhooks", handlers.AdminListWebhooks)
+		r.Post("/webhooks", handlers.AdminCreateWebhook)
+		r.Get("/webhooks/{id}", handlers.AdminGetWebhook)
+		r.Patch("/webhooks/{id}", handlers.AdminUpdateWebhook)
+		r.Delete("/webhooks/{id}", handlers.AdminDeleteWebhook)
+		r.Post("/webhooks/{id}/ping", handlers.AdminPingWebhook)
+		r.Get("/webhooks/{id}/deliveries", handlers.AdminListWebhookDeliveries)
+		r.Get("/webhooks/{id}/deliveries/{did}", handlers.AdminGetWebhookDelivery)
+		r.Post("/webhooks/{id}/deliveries/{did}/retry", handlers.AdminRetryWebhookDelivery)
+
+		r.Get("/llm/usage", handlers.AdminLLMUsage)
+	})
 
 	// ---------- Party mode ----------
 	r.Route("/api/rooms", func(r chi.Router) {
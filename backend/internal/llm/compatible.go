@@ -0,0 +1,43 @@
+// internal/llm/compatible.go
+package llm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Compatible คุยกับ server ที่เลียนแบบ OpenAI Chat Completions (POST {BaseURL}/chat/completions)
+// เช่น Ollama, llama.cpp server, LM Studio, vLLM ที่รันในเครื่อง
+type Compatible struct {
+	BaseURL    string // เช่น http://localhost:11434/v1
+	APIKey     string // ส่วนใหญ่ไม่ต้องใช้
+	Model      string
+	HTTPClient *http.Client
+}
+
+func (c *Compatible) Name() string { return "local" }
+
+func (c *Compatible) Complete(ctx context.Context, req Request) (Response, error) {
+	payload := map[string]any{
+		"model":      c.Model,
+		"messages":   withSystem(req.Messages),
+		"max_tokens": maxTokens(req),
+		"stream":     false,
+	}
+	raw, err := postJSON(ctx, c.HTTPClient, c.Name(), strings.TrimRight(c.BaseURL, "/")+"/chat/completions", c.APIKey, payload)
+	if err != nil {
+		return Response{}, err
+	}
+	text := extractOutputText(raw)
+	if text == "" {
+		return Response{}, &APIError{Kind: ErrBadResponse, Provider: c.Name(), StatusCode: http.StatusOK, Message: "no output text"}
+	}
+	model, _ := raw["model"].(string)
+	return Response{
+		Text:     text,
+		Model:    firstNonEmpty(model, c.Model),
+		Provider: c.Name(),
+		Usage:    usageFrom(raw, "prompt_tokens", "completion_tokens"),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCompatibleComplete(t *testing.T) {
+	srv := newStubServer(t, replyJSON(http.StatusOK, `{
+		"model": "llama3:8b",
+		"choices": [{"message": {"role": "assistant", "content": "เมี้ยว"}}],
+		"usage": {"prompt_tokens": 20, "completion_tokens": 4}
+	}`))
+	p := &Compatible{BaseURL: srv.URL + "/v1", Model: "llama3"}
+
+	resp, err := p.Complete(context.Background(), Request{Messages: testMessages, MaxOutputTokens: 80})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if srv.path != "/v1/chat/completions" {
+		t.Errorf("path = %q, want /v1/chat/completions", srv.path)
+	}
+	if srv.auth != "" {
+		t.Errorf("Authorization = %q, want none without an API key", srv.auth)
+	}
+	if srv.body["model"] != "llama3" || srv.body["max_tokens"] != float64(80) || srv.body["stream"] != false {
+		t.Errorf("body = %v", srv.body)
+	}
+	msgs, _ := srv.body["messages"].([]any)
+	if len(msgs) != 2 {
+		t.Fatalf("messages = %v, want system + user", srv.body["messages"])
+	}
+	if first, _ := msgs[0].(map[string]any); first["role"] != RoleSystem || first["content"] != "เป็นแมว" {
+		t.Errorf("messages[0] = %v", msgs[0])
+	}
+
+	want := Response{Text: "เมี้ยว", Model: "llama3:8b", Provider: "local", Usage: Usage{InputTokens: 20, OutputTokens: 4}}
+	if resp != want {
+		t.Errorf("resp = %+v, want %+v", resp, want)
+	}
+}
+
+func TestCompatibleAddsSystemPrompt(t *testing.T) {
+	srv := newStubServer(t, replyJSON(http.StatusOK, `{"choices": [{"message": {"content": "ok"}}]}`))
+	p := &Compatible{BaseURL: srv.URL, APIKey: "local-key", Model: "llama3"}
+
+	resp, err := p.Complete(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if srv.auth != "Bearer local-key" {
+		t.Errorf("Authorization = %q", srv.auth)
+	}
+	msgs, _ := srv.body["messages"].([]any)
+	if first, _ := msgs[0].(map[string]any); len(msgs) != 2 || first["role"] != RoleSystem {
+		t.Errorf("messages = %v, want the default system prompt first", srv.body["messages"])
+	}
+	if resp.Model != "llama3" || resp.Usage != (Usage{}) {
+		t.Errorf("resp = %+v, want configured model and no usage", resp)
+	}
+}
+
+func TestCompatibleStatusErrors(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{http.StatusUnauthorized, ErrMisconfigured},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusBadGateway, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code), func(t *testing.T) {
+			srv := newStubServer(t, replyJSON(tt.code, `{"error": "model is loading"}`))
+			p := &Compatible{BaseURL: srv.URL, Model: "llama3"}
+
+			_, err := p.Complete(context.Background(), Request{Messages: testMessages})
+			var apiErr *APIError
+			if !errors.Is(err, tt.want) || !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if apiErr.Provider != "local" || apiErr.Message != "model is loading" {
+				t.Errorf("APIError = %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestCompatibleClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := newStubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+	// timeout จาก http.Client (ไม่ใช่ ctx) ก็ต้องเป็น ErrTimeout
+	p := &Compatible{BaseURL: srv.URL, Model: "llama3", HTTPClient: &http.Client{Timeout: 50 * time.Millisecond}}
+
+	if _, err := p.Complete(context.Background(), Request{Messages: testMessages}); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+}
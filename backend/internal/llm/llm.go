@@ -0,0 +1,199 @@
+// internal/llm/llm.go
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Role ของข้อความในบทสนทนา
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request คือคำขอหนึ่งครั้ง — Messages เรียงเก่าไปใหม่ (system อยู่หน้าสุดถ้ามี)
+type Request struct {
+	Messages        []Message
+	MaxOutputTokens int // 0 = MaxOutputTokens()
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type Response struct {
+	Text     string
+	Model    string
+	Provider string
+	Usage    Usage
+}
+
+// Provider คือผู้ให้บริการ LLM — เปลี่ยนได้ด้วย SetProvider หรือ LLM_PROVIDER
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, req Request) (Response, error)
+}
+
+// error ที่ provider คืน (ห่อด้วย *APIError ได้) — handler ใช้ errors.Is แปลงเป็น status code
+var (
+	ErrMisconfigured = errors.New("llm: provider not configured")
+	ErrTimeout       = errors.New("llm: timeout")
+	ErrRateLimited   = errors.New("llm: rate limited")
+	ErrRejected      = errors.New("llm: request rejected") // เกิน context/ติด content filter
+	ErrUnavailable   = errors.New("llm: provider unavailable")
+	ErrBadResponse   = errors.New("llm: bad response")
+)
+
+// APIError คือคำตอบที่ไม่สำเร็จจาก provider พร้อมชนิดของ error (Kind)
+type APIError struct {
+	Kind       error
+	Provider   string
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // จาก header Retry-After (ถ้ามี)
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s %d: %s", e.Kind, e.Provider, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error { return e.Kind }
+
+// ===== config =====
+//
+//	LLM_PROVIDER           mock (default) | openai | local
+//	LLM_TIMEOUT            timeout ต่อคำขอ (default 12s — ต้องน้อยกว่า timeout ของ router)
+//	LLM_MAX_OUTPUT_TOKENS  default 512
+//	LLM_STREAM_TIMEOUT     timeout ของ /api/chat/stream (default 2m)
+//	LLM_SYSTEM_PROMPT      default "ตอบสั้น กระชับ"
+//	LLM_SYSTEM_PROMPT_<USE_CASE>  prompt แยกตาม use case (ดู prompt.go)
+//	LLM_CONTEXT_TOKENS     งบ token ของประวัติที่ส่งต่อครั้ง (default 3000)
+//	OPENAI_API_KEY, OPENAI_MODEL (gpt-4.1-mini), OPENAI_BASE_URL (https://api.openai.com/v1)
+//	LOCAL_LLM_BASE_URL (http://localhost:11434/v1), LOCAL_LLM_MODEL (llama3.1), LOCAL_LLM_API_KEY (ไม่บังคับ)
+
+func Timeout() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("LLM_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return 12 * time.Second
+}
+
+func MaxOutputTokens() int {
+	if n, err := strconv.Atoi(os.Getenv("LLM_MAX_OUTPUT_TOKENS")); err == nil && n > 0 {
+		return n
+	}
+	return 512
+}
+
+func SystemPrompt() string {
+	if s := strings.TrimSpace(os.Getenv("LLM_SYSTEM_PROMPT")); s != "" {
+		return s
+	}
+	return defaultPrompts[UseCaseGeneral]
+}
+
+func env(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// FromEnv สร้าง provider ตาม LLM_PROVIDER
+func FromEnv() (Provider, error) {
+	switch name := strings.ToLower(env("LLM_PROVIDER", "mock")); name {
+	case "mock":
+		return Mock{}, nil
+	case "openai":
+		key := os.Getenv("OPENAI_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("%w: OPENAI_API_KEY not set", ErrMisconfigured)
+		}
+		return &OpenAI{
+			BaseURL: env("OPENAI_BASE_URL", "https://api.openai.com/v1"),
+			APIKey:  key,
+			Model:   env("OPENAI_MODEL", "gpt-4.1-mini"),
+		}, nil
+	case "local":
+		return &Compatible{
+			BaseURL: env("LOCAL_LLM_BASE_URL", "http://localhost:11434/v1"),
+			APIKey:  os.Getenv("LOCAL_LLM_API_KEY"),
+			Model:   env("LOCAL_LLM_MODEL", "llama3.1"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown LLM_PROVIDER %q", ErrMisconfigured, name)
+	}
+}
+
+var (
+	providerMu sync.RWMutex
+	provider   Provider
+)
+
+// SetProvider เปลี่ยน provider ที่ใช้ทั้งระบบ
+func SetProvider(p Provider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	provider = p
+}
+
+// Current: ยังไม่ SetProvider = สร้างจาก env ครั้งแรกที่ใช้
+// ตั้งค่าผิด → provider ที่ตอบ ErrMisconfigured ทุกครั้ง (ไม่แอบใช้ mock แทน)
+func Current() Provider {
+	providerMu.RLock()
+	p := provider
+	providerMu.RUnlock()
+	if p != nil {
+		return p
+	}
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider == nil {
+		p, err := FromEnv()
+		if err != nil {
+			log.Printf("llm: %v", err)
+			p = broken{err: err}
+		}
+		provider = p
+		log.Printf("llm: using provider %s", provider.Name())
+	}
+	return provider
+}
+
+type broken struct{ err error }
+
+func (b broken) Name() string { return "unconfigured" }
+func (b broken) Complete(context.Context, Request) (Response, error) {
+	return Response{}, b.err
+}
+
+// withSystem เติม system prompt หน้าสุดถ้ายังไม่มี
+func withSystem(msgs []Message) []Message {
+	if len(msgs) > 0 && msgs[0].Role == RoleSystem {
+		return msgs
+	}
+	return append([]Message{{Role: RoleSystem, Content: SystemPrompt()}}, msgs...)
+}
+
+// EstimateTokens ประมาณจำนวน token แบบหยาบ (~4 byte ต่อ token) ใช้เมื่อ provider ไม่บอก
+func EstimateTokens(s string) int {
+	if s == "" {
+		return 0
+	}
+	return len(s)/4 + 1
+}
@@ -0,0 +1,56 @@
+// internal/llm/mock.go
+package llm
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Mock ตอบทวนข้อความล่าสุดของ user — ใช้ตอน dev (ไม่เสียเงิน)
+type Mock struct{}
+
+func (Mock) Name() string { return "mock" }
+
+func (Mock) Complete(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, ErrTimeout
+	}
+	var last string
+	in := 0
+	for _, m := range req.Messages {
+		in += EstimateTokens(m.Content)
+		if m.Role == RoleUser {
+			last = m.Content
+		}
+	}
+	text := "นี่คือข้อความ mock จาก backend: " + last
+	return Response{
+		Text:     text,
+		Model:    "mock",
+		Provider: "mock",
+		Usage:    Usage{InputTokens: in, OutputTokens: EstimateTokens(text)},
+	}, nil
+}
+
+// Stream ส่งทีละคำ (หน่วงนิดหน่อยให้เห็นการไหลตอน dev)
+func (m Mock) Stream(ctx context.Context, req Request, onDelta func(string) error) (Response, error) {
+	resp, err := m.Complete(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	words := strings.SplitAfter(resp.Text, " ")
+	for i, w := range words {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return resp, ctx.Err()
+			case <-time.After(30 * time.Millisecond):
+			}
+		}
+		if err := onDelta(w); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,242 @@
+// internal/llm/openai.go
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAI ใช้ Responses API (POST {BaseURL}/responses)
+type OpenAI struct {
+	BaseURL    string // เช่น https://api.openai.com/v1
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client // nil = http.DefaultClient (timeout มาจาก ctx)
+}
+
+func (o *OpenAI) Name() string { return "openai" }
+
+func (o *OpenAI) Complete(ctx context.Context, req Request) (Response, error) {
+	instructions, input := splitSystem(req.Messages)
+	payload := map[string]any{
+		"model":             o.Model,
+		"instructions":      instructions,
+		"input":             input,
+		"max_output_tokens": maxTokens(req),
+	}
+	raw, err := postJSON(ctx, o.HTTPClient, o.Name(), strings.TrimRight(o.BaseURL, "/")+"/responses", o.APIKey, payload)
+	if err != nil {
+		return Response{}, err
+	}
+	text := extractOutputText(raw)
+	if text == "" {
+		return Response{}, &APIError{Kind: ErrBadResponse, Provider: o.Name(), StatusCode: http.StatusOK, Message: "no output text"}
+	}
+	model, _ := raw["model"].(string)
+	return Response{
+		Text:     text,
+		Model:    firstNonEmpty(model, o.Model),
+		Provider: o.Name(),
+		Usage:    usageFrom(raw, "input_tokens", "output_tokens"),
+	}, nil
+}
+
+// splitSystem: system → instructions, ที่เหลือเป็น input ตามลำดับ
+func splitSystem(msgs []Message) (string, []Message) {
+	var instructions string
+	input := make([]Message, 0, len(msgs))
+	for _, m := range withSystem(msgs) {
+		if m.Role == RoleSystem {
+			instructions = strings.TrimSpace(instructions + "\n" + m.Content)
+			continue
+		}
+		input = append(input, m)
+	}
+	return instructions, input
+}
+
+// --- ฟังก์ชันช่วย: ดึงข้อความจาก response รูปแบบต่างๆ ของ Responses API ---
+// (รองรับ choices แบบ chat completions ด้วย — Compatible ใช้ตัวเดียวกัน)
+func extractOutputText(raw map[string]any) string {
+	// 1) ทางลัด: ถ้ามี output_text ใช้เลย
+	if v, ok := raw["output_text"].(string); ok && v != "" {
+		return v
+	}
+	// 2) รูปแบบหลักของ Responses API: output -> [ message ] -> content -> [ {type: output_text, text: "..."} ]
+	if out, ok := raw["output"].([]any); ok {
+		for _, item := range out {
+			msg, _ := item.(map[string]any)
+			if msg == nil {
+				continue
+			}
+			if msg["type"] == "message" {
+				if content, ok2 := msg["content"].([]any); ok2 {
+					for _, c := range content {
+						part, _ := c.(map[string]any)
+						if part == nil {
+							continue
+						}
+						// มาตรฐานใหม่จะเป็น type: "output_text"
+						if (part["type"] == "output_text" || part["type"] == "text") && part["text"] != nil {
+							if s, ok3 := part["text"].(string); ok3 && s != "" {
+								return s
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	// 3) กันเหนียว: ถ้าบางรุ่นส่งเป็น choices แบบเดิม (ไม่ค่อยเกิดใน /v1/responses)
+	if choices, ok := raw["choices"].([]any); ok && len(choices) > 0 {
+		if ch0, ok := choices[0].(map[string]any); ok {
+			if txt, ok := ch0["text"].(string); ok && txt != "" {
+				return txt
+			}
+			// รูปแบบ chat-completions เก่า
+			if msg, ok := ch0["message"].(map[string]any); ok {
+				if content, ok := msg["content"].(string); ok && content != "" {
+					return content
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// postJSON ส่ง payload แล้วคืน body ที่ decode แล้ว หรือ *APIError ที่บอกชนิดของปัญหา
+func postJSON(ctx context.Context, client *http.Client, provider, url, apiKey string, payload any) (map[string]any, error) {
+	resp, err := post(ctx, client, provider, url, apiKey, payload, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, transportError(ctx, provider, err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(respBody, &raw); err != nil {
+		return nil, &APIError{Kind: ErrBadResponse, Provider: provider, StatusCode: resp.StatusCode, Message: "invalid json"}
+	}
+	return raw, nil
+}
+
+// post ส่งคำขอ คืน response ที่เป็น 2xx (ผู้เรียกต้องปิด body) หรือ *APIError
+func post(ctx context.Context, client *http.Client, provider, url, apiKey string, payload any, accept string) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, &APIError{Kind: ErrMisconfigured, Provider: provider, Message: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, transportError(ctx, provider, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, statusError(provider, resp, b)
+	}
+	return resp, nil
+}
+
+// transportError: ต่อไม่ติด/หมดเวลา (client ยกเลิกเองคืน ctx.Err() ตามเดิม)
+func transportError(ctx context.Context, provider string, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ctx.Err()
+	}
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+		return &APIError{Kind: ErrTimeout, Provider: provider, Message: err.Error()}
+	}
+	return &APIError{Kind: ErrUnavailable, Provider: provider, Message: err.Error()}
+}
+
+// statusError แปลง status ของ provider เป็นชนิด error ของเรา
+// 401/403/404 = ตั้งค่าผิด (key/model/URL) ไม่ใช่ความผิดของผู้ใช้
+func statusError(provider string, resp *http.Response, body []byte) error {
+	e := &APIError{Provider: provider, StatusCode: resp.StatusCode, Message: errorMessage(body)}
+	switch code := resp.StatusCode; {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden || code == http.StatusNotFound:
+		e.Kind = ErrMisconfigured
+	case code == http.StatusTooManyRequests:
+		e.Kind = ErrRateLimited
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			e.RetryAfter = time.Duration(s) * time.Second
+		}
+	case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+		e.Kind = ErrTimeout
+	case code == http.StatusBadRequest || code == http.StatusRequestEntityTooLarge || code == http.StatusUnprocessableEntity:
+		e.Kind = ErrRejected
+	case code >= 500:
+		e.Kind = ErrUnavailable
+	default:
+		e.Kind = ErrBadResponse
+	}
+	return e
+}
+
+// errorMessage: {"error": {"message": "..."}} หรือ {"error": "..."} หรือ body ดิบ (ตัดให้สั้น)
+func errorMessage(body []byte) string {
+	var v struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &v) == nil && len(v.Error) > 0 {
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(v.Error, &obj) == nil && obj.Message != "" {
+			return obj.Message
+		}
+		var s string
+		if json.Unmarshal(v.Error, &s) == nil && s != "" {
+			return s
+		}
+	}
+	s := strings.TrimSpace(string(body))
+	if len(s) > 300 {
+		s = s[:300]
+	}
+	return s
+}
+
+func usageFrom(raw map[string]any, inKey, outKey string) Usage {
+	u, _ := raw["usage"].(map[string]any)
+	in, _ := u[inKey].(float64)
+	out, _ := u[outKey].(float64)
+	return Usage{InputTokens: int(in), OutputTokens: int(out)}
+}
+
+func maxTokens(req Request) int {
+	if req.MaxOutputTokens > 0 {
+		return req.MaxOutputTokens
+	}
+	return MaxOutputTokens()
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
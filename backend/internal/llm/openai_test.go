@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubServer ตอบทุกคำขอด้วย handler แล้วเก็บคำขอล่าสุดไว้ตรวจ
+type stubServer struct {
+	*httptest.Server
+	path string
+	auth string
+	body map[string]any
+}
+
+func newStubServer(t *testing.T, h http.HandlerFunc) *stubServer {
+	t.Helper()
+	s := &stubServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path = r.URL.Path
+		s.auth = r.Header.Get("Authorization")
+		s.body = nil
+		_ = json.NewDecoder(r.Body).Decode(&s.body)
+		h(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func replyJSON(code int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+var testMessages = []Message{
+	{Role: RoleSystem, Content: "เป็นแมว"},
+	{Role: RoleUser, Content: "สวัสดี"},
+}
+
+func TestOpenAIComplete(t *testing.T) {
+	srv := newStubServer(t, replyJSON(http.StatusOK, `{
+		"model": "gpt-test-2024",
+		"output": [{"type": "message", "content": [{"type": "output_text", "text": "เมี้ยว"}]}],
+		"usage": {"input_tokens": 12, "output_tokens": 3}
+	}`))
+	p := &OpenAI{BaseURL: srv.URL + "/v1/", APIKey: "sk-test", Model: "gpt-test"}
+
+	resp, err := p.Complete(context.Background(), Request{Messages: testMessages, MaxOutputTokens: 50})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if srv.path != "/v1/responses" {
+		t.Errorf("path = %q, want /v1/responses", srv.path)
+	}
+	if srv.auth != "Bearer sk-test" {
+		t.Errorf("Authorization = %q", srv.auth)
+	}
+	if srv.body["model"] != "gpt-test" || srv.body["instructions"] != "เป็นแมว" || srv.body["max_output_tokens"] != float64(50) {
+		t.Errorf("body = %v", srv.body)
+	}
+	if input, _ := srv.body["input"].([]any); len(input) != 1 {
+		t.Errorf("input = %v, want only the user message", srv.body["input"])
+	}
+
+	want := Response{Text: "เมี้ยว", Model: "gpt-test-2024", Provider: "openai", Usage: Usage{InputTokens: 12, OutputTokens: 3}}
+	if resp != want {
+		t.Errorf("resp = %+v, want %+v", resp, want)
+	}
+}
+
+func TestOpenAIStatusErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		retryAfter string
+		want       error
+		wantRetry  time.Duration
+	}{
+		{"unauthorized", http.StatusUnauthorized, "", ErrMisconfigured, 0},
+		{"model not found", http.StatusNotFound, "", ErrMisconfigured, 0},
+		{"rate limited", http.StatusTooManyRequests, "7", ErrRateLimited, 7 * time.Second},
+		{"rejected", http.StatusBadRequest, "", ErrRejected, 0},
+		{"gateway timeout", http.StatusGatewayTimeout, "", ErrTimeout, 0},
+		{"server error", http.StatusInternalServerError, "", ErrUnavailable, 0},
+		{"overloaded", http.StatusServiceUnavailable, "", ErrUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newStubServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				replyJSON(tt.code, `{"error": {"message": "nope"}}`)(w, r)
+			})
+			p := &OpenAI{BaseURL: srv.URL, APIKey: "sk-test", Model: "gpt-test"}
+
+			_, err := p.Complete(context.Background(), Request{Messages: testMessages})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %T, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.code || apiErr.Message != "nope" || apiErr.RetryAfter != tt.wantRetry {
+				t.Errorf("APIError = %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestOpenAINoOutputText(t *testing.T) {
+	srv := newStubServer(t, replyJSON(http.StatusOK, `{"output": []}`))
+	p := &OpenAI{BaseURL: srv.URL, Model: "gpt-test"}
+
+	if _, err := p.Complete(context.Background(), Request{Messages: testMessages}); !errors.Is(err, ErrBadResponse) {
+		t.Fatalf("err = %v, want ErrBadResponse", err)
+	}
+}
+
+func TestOpenAITimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := newStubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+	p := &OpenAI{BaseURL: srv.URL, Model: "gpt-test"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := p.Complete(ctx, Request{Messages: testMessages}); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+}
@@ -0,0 +1,214 @@
+// internal/llm/stream.go
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Streamer คือ provider ที่ส่งคำตอบทีละส่วนได้
+// onDelta คืน error (เช่น client ปิดไปแล้ว) = หยุดและยกเลิกคำขอต้นทาง
+type Streamer interface {
+	Stream(ctx context.Context, req Request, onDelta func(string) error) (Response, error)
+}
+
+// Stream ใช้ Stream ของ provider ถ้ามี ไม่งั้น Complete แล้วส่งทั้งก้อนเป็น delta เดียว
+func Stream(ctx context.Context, p Provider, req Request, onDelta func(string) error) (Response, error) {
+	if s, ok := p.(Streamer); ok {
+		return s.Stream(ctx, req, onDelta)
+	}
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if err := onDelta(resp.Text); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// StreamTimeout: LLM_STREAM_TIMEOUT (default 2m) — stream คุมเวลาเอง ไม่ผ่าน timeout ของ router
+func StreamTimeout() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(env("LLM_STREAM_TIMEOUT", ""))); err == nil && d > 0 {
+		return d
+	}
+	return 2 * time.Minute
+}
+
+// readSSE อ่าน text/event-stream แล้วเรียก fn ทีละ event (data หลายบรรทัดต่อกันด้วย \n)
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	var event string
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(event, strings.Join(data, "\n")); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"): // comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		return fn(event, strings.Join(data, "\n"))
+	}
+	return nil
+}
+
+// errStop หยุดอ่าน stream เมื่อเจอ event จบแล้ว
+type errStop struct{}
+
+func (errStop) Error() string { return "stop" }
+
+// ===== OpenAI Responses (stream: true) =====
+
+func (o *OpenAI) Stream(ctx context.Context, req Request, onDelta func(string) error) (Response, error) {
+	instructions, input := splitSystem(req.Messages)
+	payload := map[string]any{
+		"model":             o.Model,
+		"instructions":      instructions,
+		"input":             input,
+		"max_output_tokens": maxTokens(req),
+		"stream":            true,
+	}
+	resp, err := post(ctx, o.HTTPClient, o.Name(), strings.TrimRight(o.BaseURL, "/")+"/responses", o.APIKey, payload, "text/event-stream")
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	out := Response{Model: o.Model, Provider: o.Name()}
+	var text strings.Builder
+	var failure error
+	err = readSSE(resp.Body, func(_, data string) error {
+		var ev struct {
+			Type     string         `json:"type"`
+			Delta    string         `json:"delta"`
+			Message  string         `json:"message"`
+			Response map[string]any `json:"response"`
+		}
+		if json.Unmarshal([]byte(data), &ev) != nil {
+			return nil
+		}
+		switch ev.Type {
+		case "response.output_text.delta":
+			text.WriteString(ev.Delta)
+			if err := onDelta(ev.Delta); err != nil {
+				failure = err
+				return errStop{}
+			}
+		case "response.completed", "response.incomplete": // incomplete = ชน max_output_tokens ใช้เท่าที่ได้
+			if m, _ := ev.Response["model"].(string); m != "" {
+				out.Model = m
+			}
+			out.Usage = usageFrom(ev.Response, "input_tokens", "output_tokens")
+			return errStop{}
+		case "response.failed", "error":
+			msg := ev.Message
+			if e, _ := ev.Response["error"].(map[string]any); e != nil {
+				msg, _ = e["message"].(string)
+			}
+			failure = &APIError{Kind: ErrUnavailable, Provider: o.Name(), StatusCode: http.StatusOK, Message: msg}
+			return errStop{}
+		}
+		return nil
+	})
+	return finishStream(ctx, o.Name(), out, text.String(), err, failure, req)
+}
+
+// ===== OpenAI-compatible chat completions (stream: true) =====
+
+func (c *Compatible) Stream(ctx context.Context, req Request, onDelta func(string) error) (Response, error) {
+	payload := map[string]any{
+		"model":          c.Model,
+		"messages":       withSystem(req.Messages),
+		"max_tokens":     maxTokens(req),
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": true},
+	}
+	resp, err := post(ctx, c.HTTPClient, c.Name(), strings.TrimRight(c.BaseURL, "/")+"/chat/completions", c.APIKey, payload, "text/event-stream")
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	out := Response{Model: c.Model, Provider: c.Name()}
+	var text strings.Builder
+	var failure error
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return errStop{}
+		}
+		var chunk struct {
+			Model   string `json:"model"`
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if json.Unmarshal([]byte(data), &chunk) != nil {
+			return nil
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.Usage = Usage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+		}
+		for _, ch := range chunk.Choices {
+			if d := ch.Delta.Content; d != "" {
+				text.WriteString(d)
+				if err := onDelta(d); err != nil {
+					failure = err
+					return errStop{}
+				}
+			}
+		}
+		return nil
+	})
+	return finishStream(ctx, c.Name(), out, text.String(), err, failure, req)
+}
+
+// finishStream รวมผลหลังอ่าน stream จบ: แปลง error และประมาณ usage ถ้า provider ไม่บอก
+// failure = error จาก provider (event error) หรือจาก onDelta ส่งคืนตามเดิม
+func finishStream(ctx context.Context, provider string, out Response, text string, readErr, failure error, req Request) (Response, error) {
+	out.Text = text
+	if failure != nil {
+		return out, failure
+	}
+	if _, stopped := readErr.(errStop); readErr != nil && !stopped {
+		return out, transportError(ctx, provider, readErr)
+	}
+	if text == "" {
+		return out, &APIError{Kind: ErrBadResponse, Provider: provider, StatusCode: http.StatusOK, Message: "empty stream"}
+	}
+	if out.Usage == (Usage{}) {
+		for _, m := range withSystem(req.Messages) {
+			out.Usage.InputTokens += EstimateTokens(m.Content)
+		}
+		out.Usage.OutputTokens = EstimateTokens(text)
+	}
+	return out, nil
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"my-app-backend/internal/auth"
+)
+
+// RequireAdmin ให้ผ่านเฉพาะ request ที่มี X-Admin-Key ถูกต้อง แล้วแนบชื่อ admin ไว้ใน context
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := auth.AdminName(strings.TrimSpace(r.Header.Get(auth.AdminKeyHeader)))
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error": "admin key required",
+				"code":  http.StatusText(http.StatusUnauthorized),
+			})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithAdmin(r.Context(), name)))
+	})
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+)
+
+// Authenticate อ่าน token ของผู้เล่นแล้วแนบ auth.Identity ไว้ใน context
+//
+//	Authorization: Bearer <access token>   บัญชีที่ login (มาก่อน)
+//	X-Player-Token: <device token>         guest
+//
+// ไม่ส่ง token มาเลย = ผ่านไปแบบไม่ระบุตัวตน ส่งมาแต่ไม่ถูกต้อง/หมดอายุ = 401
+// ยกเว้น /health และ /api/auth/* ที่ถือว่าไม่ระบุตัวตนแทน — client ที่ access token หมดอายุ
+// ต้อง refresh/login/logout (ด้วย refresh token) ได้ แม้จะยังแนบ token เก่ามา
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := identify(r)
+		if err != nil && anonymousOnBadToken(r.URL.Path) {
+			id, err = auth.Identity{}, nil
+		}
+		if err != nil && !isAuthError(err) {
+			log.Printf("authenticate: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error": "cannot verify player",
+				"code":  http.StatusText(http.StatusServiceUnavailable),
+			})
+			return
+		}
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+				"code":  http.StatusText(http.StatusUnauthorized),
+			})
+			return
+		}
+		if id.PlayerID != "" {
+			r = r.WithContext(auth.WithIdentity(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func identify(r *http.Request) (auth.Identity, error) {
+	if h := r.Header.Get("Authorization"); h != "" {
+		tok, ok := strings.CutPrefix(h, "Bearer ")
+		if !ok {
+			return auth.Identity{}, auth.ErrInvalidToken
+		}
+		pid, sid, err := auth.VerifyAccessToken(strings.TrimSpace(tok), time.Now())
+		if err != nil {
+			return auth.Identity{}, err
+		}
+		if auth.IsSessionRevoked(sid) {
+			return auth.Identity{}, auth.ErrInvalidToken
+		}
+		revoked, err := sessionRevokedCached(r.Context(), sid)
+		if err != nil {
+			return auth.Identity{}, err
+		}
+		if revoked {
+			auth.RevokeSession(sid) // ครั้งต่อไปไม่ต้องถามฐานข้อมูล
+			return auth.Identity{}, auth.ErrInvalidToken
+		}
+		return auth.Identity{PlayerID: pid, SessionID: sid}, nil
+	}
+	if tok := strings.TrimSpace(r.Header.Get(auth.DeviceTokenHeader)); tok != "" {
+		pid, err := auth.VerifyDeviceToken(tok)
+		if err != nil {
+			return auth.Identity{}, err
+		}
+		// device token ไม่มีวันหมดอายุ และ upgrade แล้วยังเป็น id เดิม
+		// ใช้ได้เฉพาะตอนยังเป็น guest (บัญชีต้อง login ด้วยรหัสผ่าน) guest ที่ถูก merge/ลบไปแล้วก็ใช้ไม่ได้
+		kind, err := playerKind(r.Context(), pid)
+		if errors.Is(err, db.ErrNoPlayer) || (err == nil && kind != "guest") {
+			return auth.Identity{}, auth.ErrInvalidToken
+		}
+		if err != nil {
+			return auth.Identity{}, err
+		}
+		return auth.Identity{PlayerID: pid}, nil
+	}
+	return auth.Identity{}, nil
+}
+
+func anonymousOnBadToken(path string) bool {
+	return path == "/health" || strings.HasPrefix(path, "/api/auth/")
+}
+
+// playerKind อ่านจากฐานข้อมูล (เทสแทนด้วยของปลอมได้)
+var playerKind = func(ctx context.Context, id string) (string, error) {
+	p, err := db.LookupPlayer(ctx, id)
+	return p.Kind, err
+}
+
+// sessionRevoked อ่าน sessions.revoked_at (ไม่มีแถว = ถูกลบไปพร้อมบัญชี ถือว่ายกเลิกแล้ว)
+var sessionRevoked = func(ctx context.Context, sid string) (bool, error) {
+	s, err := db.GetSession(ctx, sid)
+	if errors.Is(err, db.ErrNoSession) {
+		return true, nil
+	}
+	return s.RevokedAt != nil, err
+}
+
+// logout จาก instance อื่น (หรือก่อน restart) เห็นผลภายใน sessionCheckTTL
+// ไม่ต้องถามฐานข้อมูลทุก request ที่ใช้ access token
+const sessionCheckTTL = 30 * time.Second
+
+var (
+	sessionCheckMu sync.Mutex
+	sessionChecked = map[string]time.Time{} // session ที่ยังใช้ได้ → ถามใหม่หลังเวลานี้
+)
+
+func sessionRevokedCached(ctx context.Context, sid string) (bool, error) {
+	now := time.Now()
+	sessionCheckMu.Lock()
+	until, ok := sessionChecked[sid]
+	sessionCheckMu.Unlock()
+	if ok && now.Before(until) {
+		return false, nil
+	}
+	revoked, err := sessionRevoked(ctx, sid)
+	if err != nil || revoked {
+		return revoked, err
+	}
+	sessionCheckMu.Lock()
+	for id, u := range sessionChecked {
+		if now.After(u) {
+			delete(sessionChecked, id)
+		}
+	}
+	sessionChecked[sid] = now.Add(sessionCheckTTL)
+	sessionCheckMu.Unlock()
+	return false, nil
+}
+
+// isAuthError: token เองผิด (ไม่ใช่ตรวจไม่ได้เพราะฐานข้อมูลมีปัญหา)
+func isAuthError(err error) bool {
+	return errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrExpiredToken)
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"my-app-backend/internal/auth"
+	"my-app-backend/internal/db"
+)
+
+// stubPlayers แทน playerKind ด้วย map id → kind (ไม่มีใน map = ErrNoPlayer)
+func stubPlayers(t *testing.T, kinds map[string]string) {
+	t.Helper()
+	orig := playerKind
+	playerKind = func(_ context.Context, id string) (string, error) {
+		if k, ok := kinds[id]; ok {
+			return k, nil
+		}
+		return "", db.ErrNoPlayer
+	}
+	t.Cleanup(func() { playerKind = orig })
+}
+
+// serve ส่งคำขอผ่าน Authenticate คืน status และ player id ที่ handler เห็น
+func serve(t *testing.T, req *http.Request) (int, string) {
+	t.Helper()
+	var seen string
+	h := Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := auth.FromContext(r.Context()); ok {
+			seen = id.PlayerID
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code, seen
+}
+
+func TestDeviceTokenGuest(t *testing.T) {
+	stubPlayers(t, map[string]string{"g1": "guest"})
+	req := httptest.NewRequest(http.MethodGet, "/api/players/me", nil)
+	req.Header.Set(auth.DeviceTokenHeader, auth.IssueDeviceToken("g1"))
+
+	if code, pid := serve(t, req); code != http.StatusOK || pid != "g1" {
+		t.Fatalf("got %d %q, want 200 as g1", code, pid)
+	}
+}
+
+func TestDeviceTokenAfterUpgrade(t *testing.T) {
+	// UpgradeGuest เก็บ id เดิมไว้ — token ของเครื่อง guest ต้องใช้ไม่ได้อีก
+	stubPlayers(t, map[string]string{"g1": "registered"})
+	req := httptest.NewRequest(http.MethodGet, "/api/players/g1/export", nil)
+	req.Header.Set(auth.DeviceTokenHeader, auth.IssueDeviceToken("g1"))
+
+	if code, pid := serve(t, req); code != http.StatusUnauthorized || pid != "" {
+		t.Fatalf("got %d %q, want 401", code, pid)
+	}
+}
+
+func TestDeviceTokenDeletedPlayer(t *testing.T) {
+	stubPlayers(t, map[string]string{})
+	req := httptest.NewRequest(http.MethodGet, "/api/players/me", nil)
+	req.Header.Set(auth.DeviceTokenHeader, auth.IssueDeviceToken("merged-guest"))
+
+	if code, _ := serve(t, req); code != http.StatusUnauthorized {
+		t.Fatalf("got %d, want 401", code)
+	}
+}
+
+func TestDeviceTokenLookupError(t *testing.T) {
+	orig := playerKind
+	playerKind = func(context.Context, string) (string, error) { return "", errors.New("db down") }
+	t.Cleanup(func() { playerKind = orig })
+	req := httptest.NewRequest(http.MethodGet, "/api/players/me", nil)
+	req.Header.Set(auth.DeviceTokenHeader, auth.IssueDeviceToken("g1"))
+
+	if code, _ := serve(t, req); code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d, want 503", code)
+	}
+}
+
+func TestExpiredAccessToken(t *testing.T) {
+	tok, _ := auth.IssueAccessToken("p1", "s1", time.Now().Add(-time.Hour))
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/players/me", http.StatusUnauthorized},
+		// refresh/logout ด้วย refresh token ต้องใช้ได้แม้ยังแนบ access token ที่หมดอายุมา
+		{"/api/auth/refresh", http.StatusOK},
+		{"/api/auth/logout", http.StatusOK},
+		{"/health", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		if code, pid := serve(t, req); code != tt.want || pid != "" {
+			t.Errorf("%s: got %d %q, want %d anonymous", tt.path, code, pid, tt.want)
+		}
+	}
+}
+
+func TestAccessTokenRevokedElsewhere(t *testing.T) {
+	// logout จาก instance อื่น: ในหน่วยความจำไม่รู้ แต่ sessions.revoked_at ถูกตั้งแล้ว
+	revoked := map[string]bool{"s-out": true}
+	calls := 0
+	orig := sessionRevoked
+	sessionRevoked = func(_ context.Context, sid string) (bool, error) {
+		calls++
+		return revoked[sid], nil
+	}
+	t.Cleanup(func() { sessionRevoked = orig })
+
+	for sid, want := range map[string]int{"s-out": http.StatusUnauthorized, "s-in": http.StatusOK} {
+		tok, _ := auth.IssueAccessToken("p1", sid, time.Now())
+		for range 2 {
+			req := httptest.NewRequest(http.MethodGet, "/api/players/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tok)
+			if code, _ := serve(t, req); code != want {
+				t.Fatalf("%s: got %d, want %d", sid, code, want)
+			}
+		}
+	}
+	if calls != 2 {
+		t.Errorf("session looked up %d times, want once per session (cached)", calls)
+	}
+}
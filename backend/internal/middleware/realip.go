@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// RealIP แทน chi middleware.RealIP: เชื่อ X-Forwarded-For / X-Real-IP เฉพาะเมื่อ
+// connection มาจาก proxy ที่ตั้งไว้ใน TRUSTED_PROXIES เท่านั้น (ไม่งั้นใช้ที่อยู่ของ socket)
+// — client ปลอม header เองเพื่อหลบ rate limit ต่อ IP ไม่ได้
+//
+//	TRUSTED_PROXIES  CIDR หรือ IP คั่นด้วย comma เช่น "10.0.0.0/8,127.0.0.1"
+//	                 "private" = วง private/loopback ทั้งหมด (proxy ของ host อยู่ในเครือข่ายภายใน)
+//	                 ว่าง = ไม่เชื่อ header เลย
+func RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := realIP(r, trustedProxies()); ip != "" {
+			r.RemoteAddr = net.JoinHostPort(ip, "0")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+var (
+	trustedOnce sync.Once
+	trusted     []*net.IPNet
+)
+
+var privateNets = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "127.0.0.0/8", "fc00::/7", "::1/128"}
+
+func trustedProxies() []*net.IPNet {
+	trustedOnce.Do(func() { trusted = ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES")) })
+	return trusted
+}
+
+// CheckTrustedProxies เรียกตอนเริ่ม server: เตือนถ้าไม่ได้ตั้ง TRUSTED_PROXIES
+// หลัง proxy ของ host (เช่น Render) ทุก request จะเห็นเป็น IP ของ proxy ตัวเดียว
+// rate limit ต่อ IP และ IP ที่เก็บกับ feedback/moderation flag จะกลายเป็นค่าเดียวทั้งเว็บ
+func CheckTrustedProxies() {
+	if nets := trustedProxies(); len(nets) == 0 {
+		log.Printf("WARNING: TRUSTED_PROXIES is not set — X-Forwarded-For is ignored and per-IP limits see the socket address (set TRUSTED_PROXIES=private behind Render or another reverse proxy)")
+	} else {
+		log.Printf("trusted proxies: %d network(s)", len(nets))
+	}
+}
+
+// ParseTrustedProxies อ่านรูปแบบเดียวกับ TRUSTED_PROXIES (ค่าที่อ่านไม่ได้ข้ามและ log ไว้)
+func ParseTrustedProxies(spec string) []*net.IPNet {
+	var out []*net.IPNet
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		switch {
+		case s == "":
+			continue
+		case strings.EqualFold(s, "private"):
+			out = append(out, ParseTrustedProxies(strings.Join(privateNets, ","))...)
+			continue
+		case !strings.Contains(s, "/"):
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			log.Printf("TRUSTED_PROXIES: skip %q: %v", s, err)
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func isTrusted(ip net.IP, nets []*net.IPNet) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// realIP: "" = ใช้ RemoteAddr เดิม
+// X-Forwarded-For ไล่จากขวา (proxy ใกล้สุด) ข้าม proxy ที่เชื่อถือ ตัวแรกที่ไม่ใช่คือ client
+// (ค่าทางซ้ายที่ client ใส่มาเองจึงไม่มีผล)
+func realIP(r *http.Request, nets []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer := net.ParseIP(host)
+	if peer == nil || !isTrusted(peer, nets) {
+		return ""
+	}
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return "" // รูปแบบเสีย ไม่เดา
+			}
+			if i == 0 || !isTrusted(ip, nets) {
+				return ip.String()
+			}
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// EchoRequestID ส่ง request id (จาก chi middleware.RequestID) กลับใน X-Request-ID
+// ให้ client/ผู้ใช้แนบมาตอนแจ้งปัญหา แล้วเทียบกับ log ฝั่ง server ได้
+// ต้องวางหลัง middleware.RequestID
+func EchoRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := chimw.GetReqID(r.Context()); id != "" {
+			w.Header().Set(chimw.RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// TimeoutExcept = chi middleware.Timeout ยกเว้น path ที่ระบุ (เช่น SSE ที่เปิดค้างนานกว่า d)
+// path ที่ยกเว้นต้องคุมเวลาเอง
+func TimeoutExcept(d time.Duration, paths ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		skip[p] = true
+	}
+	return func(next http.Handler) http.Handler {
+		timed := chimw.Timeout(d)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timed.ServeHTTP(w, r)
+		})
+	}
+}
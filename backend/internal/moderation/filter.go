@@ -0,0 +1,306 @@
+// internal/moderation/filter.go
+package moderation
+
+import (
+	_ "embed"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Filter หาคำต้องห้ามในข้อความ — เปลี่ยนตัวกรองได้ด้วย SetFilter
+type Filter interface {
+	Find(text string) []Match
+}
+
+// Match คือช่วงที่เจอใน text (byte offset ของข้อความต้นฉบับ)
+type Match struct {
+	Term  string
+	Start int
+	End   int
+}
+
+//go:embed words_en.txt
+var wordsEN string
+
+//go:embed words_th.txt
+var wordsTH string
+
+var (
+	filterMu sync.RWMutex
+	filter   Filter
+)
+
+// SetFilter เปลี่ยนตัวกรองที่ใช้ทั้งระบบ
+func SetFilter(f Filter) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	filter = f
+}
+
+func current() Filter {
+	filterMu.RLock()
+	f := filter
+	filterMu.RUnlock()
+	if f != nil {
+		return f
+	}
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	if filter == nil {
+		filter = DefaultWordList()
+	}
+	return filter
+}
+
+// DefaultWordList: รายการคำไทย + อังกฤษที่ฝังมากับโปรแกรม
+// เพิ่มคำเองได้ทาง MODERATION_EXTRA_WORDS (คั่นด้วย comma ใช้ไวยากรณ์เดียวกับไฟล์)
+func DefaultWordList() *WordList {
+	lines := strings.Split(wordsEN+"\n"+wordsTH, "\n")
+	lines = append(lines, strings.Split(os.Getenv("MODERATION_EXTRA_WORDS"), ",")...)
+	return NewWordList(lines)
+}
+
+// WordList จับคำจากรายการ โดยกันการเลี่ยงแบบง่าย ๆ:
+//   - ตัวพิมพ์เล็กใหญ่ และ leetspeak (sh1t, @ss, fvck ไม่นับ)
+//   - ตัวอักษรซ้ำ (fuuuck, ควยยยย)
+//   - ขั้นด้วยเครื่องหมาย/zero-width (f.u.c.k, ค-ว-ย)
+//   - เว้นวรรคทีละตัว (f u c k, ค ว ย)
+//
+// คำไทยไม่นับถ้าตัวถัดไปเป็นสระบน/ล่างหรือวรรณยุกต์ (เสือกับ, เสือกิน — ก เป็นต้นพยางค์ถัดไป)
+// และคำปกติที่มีคำต้องห้ามซ้อนอยู่ใส่ในรายการยกเว้นได้ด้วย -คำ (-เหี้ยม, -แกงกะหรี่)
+type WordList struct {
+	terms []term
+	allow []string
+}
+
+type term struct {
+	word string
+	re   *regexp.Regexp
+}
+
+// NewWordList รับบรรทัดรูปแบบเดียวกับ words_*.txt (# = comment)
+func NewWordList(lines []string) *WordList {
+	wl := &WordList{}
+	seen := map[string]bool{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		if w, ok := strings.CutPrefix(line, "-"); ok {
+			if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+				wl.allow = append(wl.allow, w)
+			}
+			continue
+		}
+		if t, ok := compileTerm(line); ok {
+			wl.terms = append(wl.terms, t)
+		}
+	}
+	return wl
+}
+
+const (
+	notWord = `[^\p{L}\p{M}\p{N}]`
+	notMark = `(?:$|[^\p{Mn}])`       // คำไทย: ตัวถัดไปต้องไม่ใช่สระบน/ล่างหรือวรรณยุกต์
+	sep     = `[^\p{L}\p{M}\p{N}\s]*` // เครื่องหมายคั่นระหว่างตัวอักษร (ไม่รวมช่องว่าง)
+)
+
+// ตัวอักษรที่มักถูกแทนด้วยตัวเลข/สัญลักษณ์
+var leet = map[rune]string{
+	'a': "a4@", 'b': "b8", 'e': "e3", 'g': "g9", 'i': "i1!|",
+	'l': "l1|", 'o': "o0", 's': "s5$", 't': "t7+", 'z': "z2",
+}
+
+func compileTerm(entry string) (term, bool) {
+	exact := strings.HasPrefix(entry, "=")
+	prefix := strings.HasSuffix(entry, "*")
+	word := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(entry, "="), "*"))
+	if word == "" {
+		return term{}, false
+	}
+	ascii := isASCII(word)
+	if ascii && !prefix {
+		exact = true // คำอังกฤษจับทั้งคำ กัน class/glass/assume
+	}
+
+	parts := make([]string, 0, utf8.RuneCountInString(word))
+	for _, r := range word {
+		if chars, ok := leet[r]; ok {
+			parts = append(parts, "["+regexp.QuoteMeta(chars)+"]+")
+		} else {
+			parts = append(parts, regexp.QuoteMeta(string(r))+"+")
+		}
+	}
+	body := "(" + strings.Join(parts, sep) + ")"
+
+	pattern := body
+	switch {
+	case exact:
+		pattern = "(?:^|" + notWord + ")" + body + "(?:$|" + notWord + ")"
+	case ascii && prefix:
+		pattern = "(?:^|" + notWord + ")" + body
+	case !ascii:
+		pattern = body + notMark
+	}
+	if ascii {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return term{}, false
+	}
+	return term{word: word, re: re}, true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Find คืนทุกช่วงที่เจอ เรียงตามตำแหน่ง
+func (wl *WordList) Find(text string) []Match {
+	if text == "" {
+		return nil
+	}
+	collapsed, offs := collapseSpaced(text)
+	allowed := wl.allowedSpans(collapsed)
+	var out []Match
+	for _, t := range wl.terms {
+		pos := 0
+		for pos < len(collapsed) {
+			loc := t.re.FindStringSubmatchIndex(collapsed[pos:])
+			if loc == nil {
+				break
+			}
+			start, end := pos+loc[2], pos+loc[3]
+			if !within(allowed, start, end) {
+				out = append(out, Match{Term: t.word, Start: offs[start], End: offs[end]})
+			}
+			pos = end // ต่อจากท้ายคำ ให้ตัวคั่นหลังคำเป็นขอบของคำถัดไปได้
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
+
+// allowedSpans: ช่วง [start, end) ของคำในรายการยกเว้นที่เจอใน text
+func (wl *WordList) allowedSpans(text string) [][2]int {
+	if len(wl.allow) == 0 {
+		return nil
+	}
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		lower = text // ตัวพิมพ์เล็กยาวไม่เท่าเดิม offset จะเพี้ยน ใช้ต้นฉบับแทน
+	}
+	var spans [][2]int
+	for _, w := range wl.allow {
+		for pos := 0; ; {
+			i := strings.Index(lower[pos:], w)
+			if i < 0 {
+				break
+			}
+			spans = append(spans, [2]int{pos + i, pos + i + len(w)})
+			pos += i + len(w)
+		}
+	}
+	return spans
+}
+
+func within(spans [][2]int, start, end int) bool {
+	for _, s := range spans {
+		if start >= s[0] && end <= s[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSpaced รวมคำที่ถูกเว้นวรรคทีละตัว ("f u c k" → "fuck") และคืนตาราง
+// offs[i] = byte offset ใน text ของ byte i ใน collapsed (ยาว len(collapsed)+1)
+func collapseSpaced(text string) (string, []int) {
+	type tok struct {
+		start, end int
+		single     bool
+	}
+	var toks []tok
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			i += n
+			continue
+		}
+		start, letters := i, 0
+		for i < len(text) {
+			r, n = utf8.DecodeRuneInString(text[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			if !unicode.Is(unicode.M, r) {
+				letters++
+			}
+			i += n
+		}
+		toks = append(toks, tok{start: start, end: i, single: letters == 1})
+	}
+
+	var b strings.Builder
+	offs := make([]int, 0, len(text)+1)
+	emit := func(from, to int) {
+		b.WriteString(text[from:to])
+		for k := from; k < to; k++ {
+			offs = append(offs, k)
+		}
+	}
+	prev := 0
+	for i, t := range toks {
+		// ช่องว่างระหว่าง token ตัวเดียวสองตัวติดกันให้ตัดทิ้ง
+		if !(i > 0 && t.single && toks[i-1].single) {
+			emit(prev, t.start)
+		}
+		emit(t.start, t.end)
+		prev = t.end
+	}
+	emit(prev, len(text))
+	offs = append(offs, len(text))
+	return b.String(), offs
+}
+
+// MaskText แทนช่วงที่เจอด้วย * (เว้นช่องว่างไว้ตามเดิม)
+func MaskText(text string, ms []Match) string {
+	if len(ms) == 0 {
+		return text
+	}
+	var b strings.Builder
+	pos := 0
+	for _, m := range ms {
+		if m.End <= pos {
+			continue
+		}
+		if m.Start > pos {
+			b.WriteString(text[pos:m.Start])
+		} else {
+			m.Start = pos
+		}
+		for _, r := range text[m.Start:m.End] {
+			if unicode.IsSpace(r) {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('*')
+			}
+		}
+		pos = m.End
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
@@ -0,0 +1,63 @@
+// internal/moderation/policy.go
+package moderation
+
+import (
+	"os"
+	"strings"
+)
+
+// Action คือสิ่งที่ทำเมื่อเจอคำต้องห้าม
+type Action string
+
+const (
+	ActionAllow  Action = "allow"  // ปล่อยผ่าน
+	ActionReject Action = "reject" // ปฏิเสธ request
+	ActionMask   Action = "mask"   // แทนคำด้วย *
+	ActionFlag   Action = "flag"   // เก็บข้อความเดิม + บันทึกให้ admin ตรวจ
+)
+
+// Field แยกนโยบายตามชนิดข้อความ
+const (
+	FieldName    = "name"    // ชื่อผู้เล่น / username / ชื่อในห้อง
+	FieldMessage = "message" // ข้อความ feedback
+)
+
+// ActionFor: MODERATION_NAME_ACTION (default reject), MODERATION_MESSAGE_ACTION (default flag)
+func ActionFor(field string) Action {
+	def := ActionFlag
+	if field == FieldName {
+		def = ActionReject
+	}
+	switch a := Action(strings.ToLower(strings.TrimSpace(os.Getenv("MODERATION_" + strings.ToUpper(field) + "_ACTION")))); a {
+	case ActionAllow, ActionReject, ActionMask, ActionFlag:
+		return a
+	}
+	return def
+}
+
+// Decision ผลของ Review
+type Decision struct {
+	Action Action
+	Text   string   // ข้อความที่ควรเก็บ (mask แล้วถ้า Action = mask)
+	Terms  []string // คำที่เจอ (ไม่ซ้ำ)
+}
+
+// Review ตรวจ text ตามนโยบายของ field
+func Review(field, text string) Decision {
+	ms := current().Find(text)
+	if len(ms) == 0 {
+		return Decision{Action: ActionAllow, Text: text}
+	}
+	d := Decision{Action: ActionFor(field), Text: text}
+	seen := map[string]bool{}
+	for _, m := range ms {
+		if !seen[m.Term] {
+			seen[m.Term] = true
+			d.Terms = append(d.Terms, m.Term)
+		}
+	}
+	if d.Action == ActionMask {
+		d.Text = MaskText(text, ms)
+	}
+	return d
+}
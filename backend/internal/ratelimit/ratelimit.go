@@ -0,0 +1,95 @@
+// internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter: token bucket ต่อ key (เช่น IP หรือ player id) เก็บในหน่วยความจำของ instance นี้
+// ได้ Limit ครั้งต่อ Window และสะสมคืนเรื่อย ๆ (ไม่รีเซ็ตทีเดียวตอนครบ window)
+type Limiter struct {
+	Limit  int
+	Window time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweep   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{Limit: limit, Window: window, buckets: map[string]*bucket{}}
+}
+
+// Parse อ่านรูปแบบ "5/10m" (5 ครั้งต่อ 10 นาที) ใช้ def ถ้าว่างหรือผิดรูปแบบ
+func Parse(spec string, def *Limiter) *Limiter {
+	n, d, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return def
+	}
+	limit, err := strconv.Atoi(n)
+	if err != nil || limit <= 0 {
+		return def
+	}
+	window, err := time.ParseDuration(d)
+	if err != nil || window <= 0 {
+		return def
+	}
+	return New(limit, window)
+}
+
+func (l *Limiter) String() string { return fmt.Sprintf("%d/%s", l.Limit, l.Window) }
+
+// Allow ใช้ 1 token ของ key; ไม่พอคืน false พร้อมเวลาที่ต้องรอ
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	return l.AllowN(key, 1, time.Now())
+}
+
+func (l *Limiter) AllowN(key string, n int, now time.Time) (bool, time.Duration) {
+	if l == nil || l.Limit <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+	l.cleanup(now)
+
+	rate := float64(l.Limit) / l.Window.Seconds() // token ต่อวินาที
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Limit), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.Limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		return true, 0
+	}
+	wait := time.Duration((float64(n) - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// cleanup ทิ้ง bucket ที่เต็มแล้ว (ไม่ได้ใช้นานเกิน Window) ทุก ๆ Window
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.sweep) < l.Window {
+		return
+	}
+	l.sweep = now
+	for k, b := range l.buckets {
+		if now.Sub(b.last) >= l.Window {
+			delete(l.buckets, k)
+		}
+	}
+}
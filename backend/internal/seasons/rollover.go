@@ -0,0 +1,87 @@
+// internal/seasons/rollover.go
+package seasons
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"my-app-backend/internal/db"
+)
+
+// ขอบ season ตามปฏิทินไทย เหมือน leaderboard รายวัน/สัปดาห์/เดือน
+var bangkok = func() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Bangkok"); err == nil {
+		return loc
+	}
+	return time.FixedZone("Asia/Bangkok", 7*60*60)
+}()
+
+// LengthMonths: SEASON_LENGTH_MONTHS (default 3)
+func LengthMonths() int {
+	if n, err := strconv.Atoi(os.Getenv("SEASON_LENGTH_MONTHS")); err == nil && n > 0 {
+		return n
+	}
+	return 3
+}
+
+// CheckInterval: SEASON_CHECK_INTERVAL (default 10m)
+func CheckInterval() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("SEASON_CHECK_INTERVAL")); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+// Plan: season ใหม่ต่อจาก season เก่าทันที (ยังไม่จบ หรือเพิ่งจบไม่เกินวัน ไม่งั้นเริ่มตอนนี้)
+// และจบวันที่ 1 ของเดือนหลังจากนั้น LengthMonths เดือน (เที่ยงคืนเวลาไทย)
+func Plan(last *db.Season, count int, now time.Time) (string, time.Time, time.Time) {
+	start := now
+	if last != nil && now.Sub(last.EndsAt) < 24*time.Hour {
+		start = last.EndsAt
+	}
+	t := start.In(bangkok)
+	end := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, bangkok).AddDate(0, LengthMonths(), 0)
+	return fmt.Sprintf("Season %d", count), start, end
+}
+
+// Rollover ทำหนึ่งรอบ: เปิด season ใหม่ถ้าจำเป็น (รวมถึงเปิดรอก่อน season ปัจจุบันจบ) + archive season ที่จบแล้ว
+func Rollover(ctx context.Context, now time.Time) error {
+	res, err := db.RolloverSeasons(ctx, now, Plan)
+	if err != nil {
+		return err
+	}
+	for _, s := range res.Archived {
+		log.Printf("season %d (%s) archived", s.ID, s.Name)
+	}
+	if s := res.Created; s != nil {
+		log.Printf("season %d (%s) created: %s → %s", s.ID, s.Name,
+			s.StartsAt.In(bangkok).Format(time.RFC3339), s.EndsAt.In(bangkok).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Run เรียก Rollover ทันทีแล้วทุก CheckInterval จนกว่า ctx จะถูกยกเลิก
+func Run(ctx context.Context) {
+	tick := func() {
+		c, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		if err := Rollover(c, time.Now()); err != nil {
+			log.Printf("season rollover: %v", err)
+		}
+	}
+	tick()
+	t := time.NewTicker(CheckInterval())
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			tick()
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// internal/storage/image.go
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type (png, jpeg or gif only)")
+	ErrImageTooLarge    = errors.New("image dimensions too large")
+)
+
+// MaxImagePixels กัน decompression bomb (ไฟล์เล็กแต่ขยายเป็นภาพหลายหมื่นล้าน pixel)
+// 4096×4096 ≈ 16.7 MP พอสำหรับภาพหน้าจอทุกขนาด (5K = 14.7 MP) decode แล้วไม่เกิน ~64 MB
+const MaxImagePixels = 4096 * 4096
+
+// decodeSlot: decode ทีละรูปทั้ง process — รูปเต็มขนาดกินหน่วยความจำหลายสิบ MB
+// หลายคำขอพร้อมกัน (ครั้งละสูงสุด 3 รูป) ไม่ควรทำให้ instance เล็ก ๆ หน่วยความจำหมด
+var decodeSlot = make(chan struct{}, 1)
+
+// Image คือรูปที่ตรวจและเข้ารหัสใหม่แล้ว พร้อมเก็บ
+type Image struct {
+	Data        []byte // เข้ารหัสใหม่แล้ว (ตัด EXIF/GPS และข้อมูลที่แอบมากับไฟล์)
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Thumb       []byte // JPEG ด้านยาวไม่เกิน thumbMax
+}
+
+// ProcessImage ดูชนิดไฟล์จากเนื้อหาจริง (ไม่เชื่อนามสกุล/Content-Type ที่ client ส่งมา)
+// แล้ว decode → เข้ารหัสใหม่ → ทำ thumbnail; gif เก็บแค่เฟรมแรกเป็น png
+func ProcessImage(data []byte, thumbMax int) (Image, error) {
+	var decode func([]byte) (image.Image, error)
+	switch http.DetectContentType(data) {
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case "image/gif":
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+	default:
+		return Image{}, ErrUnsupportedImage
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupportedImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxImagePixels {
+		return Image{}, ErrImageTooLarge
+	}
+	decodeSlot <- struct{}{}
+	defer func() { <-decodeSlot }()
+	src, err := decode(data)
+	if err != nil {
+		return Image{}, ErrUnsupportedImage
+	}
+
+	out := Image{Width: cfg.Width, Height: cfg.Height}
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, src, &jpeg.Options{Quality: 90})
+		out.ContentType, out.Ext = "image/jpeg", "jpg"
+	} else {
+		err = png.Encode(&buf, src)
+		out.ContentType, out.Ext = "image/png", "png"
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	out.Data = buf.Bytes()
+
+	var tb bytes.Buffer
+	if err := jpeg.Encode(&tb, Thumbnail(src, thumbMax), &jpeg.Options{Quality: 80}); err != nil {
+		return Image{}, err
+	}
+	out.Thumb = tb.Bytes()
+	return out, nil
+}
+
+// Thumbnail ย่อให้ด้านยาวไม่เกิน size โดยเฉลี่ยสีในแต่ละช่อง (สุ่มไม่เกิน 4×4 จุดต่อ pixel)
+// พื้นโปร่งใสเติมเป็นสีขาว (thumbnail เป็น JPEG)
+func Thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, h*size/w
+		} else {
+			tw, th = w*size/h, size
+		}
+	}
+	tw, th = max(tw, 1), max(th, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		y1 = max(y1, y0+1)
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			x1 = max(x1, x0+1)
+			dst.SetRGBA(x, y, average(src, x0, x1, y0, y1))
+		}
+	}
+	return dst
+}
+
+func average(src image.Image, x0, x1, y0, y1 int) color.RGBA {
+	sx, sy := max((x1-x0)/4, 1), max((y1-y0)/4, 1)
+	var r, g, bl, n uint64
+	for y := y0; y < y1; y += sy {
+		for x := x0; x < x1; x += sx {
+			cr, cg, cb, ca := src.At(x, y).RGBA()
+			// premultiplied → ทับบนพื้นขาว
+			r += uint64(cr + 0xffff - ca)
+			g += uint64(cg + 0xffff - ca)
+			bl += uint64(cb + 0xffff - ca)
+			n++
+		}
+	}
+	return color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(bl / n >> 8), 0xff}
+}
@@ -0,0 +1,129 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: not found")
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Store เก็บไฟล์ที่ผู้ใช้อัปโหลด — เปลี่ยนที่เก็บได้ด้วย SetStore (เช่น object storage)
+// key เป็น path แบบ "feedback/2026/10/<id>.png" (ใช้ / เสมอ)
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store
+)
+
+// SetStore เปลี่ยนที่เก็บไฟล์ที่ใช้ทั้งระบบ
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// Current: ยังไม่ SetStore = Local ที่ UPLOAD_DIR (default ./uploads)
+func Current() Store {
+	storeMu.RLock()
+	s := store
+	storeMu.RUnlock()
+	if s != nil {
+		return s
+	}
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store == nil {
+		dir := os.Getenv("UPLOAD_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		store = Local{Dir: dir}
+	}
+	return store
+}
+
+// Local เก็บไฟล์บนดิสก์ใต้ Dir
+type Local struct {
+	Dir string
+}
+
+func (l Local) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put เขียนไฟล์ชั่วคราวก่อนแล้ว rename (ไม่มีใครเห็นไฟล์ที่เขียนไม่ครบ)
+func (l Local) Put(_ context.Context, key string, data []byte, _ string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (l Local) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete: ไม่มีไฟล์อยู่แล้วไม่ถือเป็น error
+func (l Local) Delete(_ context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
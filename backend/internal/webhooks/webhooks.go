@@ -0,0 +1,81 @@
+// internal/webhooks/webhooks.go
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"my-app-backend/internal/db"
+)
+
+// event ที่ส่งออก ("*" ใน endpoint = รับทุก event)
+const (
+	EventFeedbackCreated = "feedback.created" // มี feedback ใหม่
+	EventScoreTop        = "score.top"        // คะแนนอันดับ 1 ใหม่ของเกมใน season ปัจจุบัน
+	EventPing            = "ping"             // ทดสอบจาก admin (ส่งเฉพาะ endpoint ที่สั่ง)
+)
+
+// Events คือ event ที่ subscribe ได้
+var Events = []string{EventFeedbackCreated, EventScoreTop}
+
+// header ที่แนบไปกับทุกคำขอ
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Envelope คือ body ที่ปลายทางได้รับ
+type Envelope struct {
+	Event      string `json:"event"`
+	OccurredAt string `json:"occurred_at"`
+	Data       any    `json:"data"`
+}
+
+// Emit เข้าคิว event ให้ทุก endpoint ที่รับ (ไม่รอส่ง — worker ใน Run จะส่งเอง)
+// playerID = เจ้าของข้อมูลใน data ("" = ไม่มี) ใช้ตอนลบข้อมูลส่วนบุคคล
+func Emit(ctx context.Context, event string, data any, playerID string) (int64, error) {
+	return enqueue(ctx, event, data, playerID, 0)
+}
+
+// Ping เข้าคิว event ทดสอบให้ endpoint เดียว
+func Ping(ctx context.Context, endpointID int64) (int64, error) {
+	return enqueue(ctx, EventPing, map[string]any{"endpoint_id": endpointID}, "", endpointID)
+}
+
+func enqueue(ctx context.Context, event string, data any, playerID string, endpointID int64) (int64, error) {
+	body, err := json.Marshal(Envelope{Event: event, OccurredAt: time.Now().UTC().Format(time.RFC3339), Data: data})
+	if err != nil {
+		return 0, err
+	}
+	return db.EnqueueWebhookEvent(ctx, event, body, playerID, endpointID)
+}
+
+// Sign คืนค่า X-Webhook-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+// ปลายทางคำนวณซ้ำแล้วเทียบแบบ constant time และปฏิเสธ timestamp ที่เก่าเกินไป (กัน replay)
+func Sign(secret string, ts time.Time, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(strconv.FormatInt(ts.Unix(), 10)))
+	m.Write([]byte("."))
+	m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify ตรวจลายเซ็นฝั่งรับ (ใช้ทดสอบกับ server จำลอง หรือเป็นตัวอย่างให้ทีมที่รับ)
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) bool {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	ts := time.Unix(sec, 0)
+	if d := now.Sub(ts); d > tolerance || d < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
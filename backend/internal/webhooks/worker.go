@@ -0,0 +1,212 @@
+// internal/webhooks/worker.go
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"my-app-backend/internal/db"
+)
+
+// ค่าตั้งจาก env (อ่านตอนใช้ เพราะ .env โหลดใน main)
+//
+//	WEBHOOK_POLL_INTERVAL  ความถี่ดึงคิว (default 5s)
+//	WEBHOOK_TIMEOUT        timeout ต่อคำขอ (default 10s)
+//	WEBHOOK_MAX_ATTEMPTS   ส่งไม่สำเร็จกี่ครั้งถึงเลิก (default 8)
+//	WEBHOOK_RETENTION      เก็บ delivery ที่จบแล้วนานเท่าไร (default 720h)
+func PollInterval() time.Duration { return envDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second) }
+func Timeout() time.Duration      { return envDuration("WEBHOOK_TIMEOUT", 10*time.Second) }
+func Retention() time.Duration    { return envDuration("WEBHOOK_RETENTION", 30*24*time.Hour) }
+
+func MaxAttempts() int {
+	if n, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return 8
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+const (
+	batchSize   = 20
+	backoffBase = 30 * time.Second
+	backoffMax  = 6 * time.Hour
+	maxErrorLen = 500
+)
+
+// Backoff: รอก่อนส่งครั้งถัดไปหลังล้มเหลวครั้งที่ attempt (เริ่ม 1)
+// 30s, 1m, 2m, 4m, ... ไม่เกิน 6 ชั่วโมง บวกลบสุ่ม 20% ไม่ให้ทุกงานยิงพร้อมกัน
+func Backoff(attempt int) time.Duration {
+	d := backoffMax
+	if attempt < 20 {
+		d = min(backoffBase<<(max(attempt, 1)-1), backoffMax)
+	}
+	jitter := (rand.Float64()*0.4 - 0.2) * float64(d)
+	return d + time.Duration(jitter)
+}
+
+// Result คือผลการส่งหนึ่งครั้ง
+type Result struct {
+	StatusCode int // 0 = ต่อไม่ติด/timeout
+	Err        string
+	Duration   time.Duration
+}
+
+// OK: ปลายทางตอบ 2xx
+func (r Result) OK() bool { return r.StatusCode >= 200 && r.StatusCode < 300 }
+
+// Deliver ส่ง job หนึ่งครั้ง ไม่แตะ DB — ทดสอบกับ httptest.Server ได้ตรง ๆ
+func Deliver(ctx context.Context, client *http.Client, job db.WebhookJob, now time.Time) Result {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return Result{Err: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pettext-webhooks/1")
+	req.Header.Set(HeaderEvent, job.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(job.DeliveryID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, now, job.Payload))
+
+	resp, err := client.Do(req)
+	res := Result{Duration: time.Since(start)}
+	if err != nil {
+		res.Err = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+	res.StatusCode = resp.StatusCode
+	if !res.OK() {
+		// เก็บต้น body ไว้ช่วย debug
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLen))
+		res.Err = fmt.Sprintf("%s: %s", resp.Status, bytes.TrimSpace(b))
+	} else {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	}
+	if len(res.Err) > maxErrorLen {
+		res.Err = res.Err[:maxErrorLen]
+	}
+	return res
+}
+
+// Outcome คือสิ่งที่ต้องทำกับ delivery หลังส่งหนึ่งครั้ง
+type Outcome struct {
+	Status          string    // db.DeliveryDelivered | db.DeliveryPending | db.DeliveryFailed
+	NextAttempt     time.Time // zero = ไม่ส่งอีก
+	DisableEndpoint bool      // ปลายทางตอบ 410 = บอกให้เลิกส่งถาวร
+}
+
+// Decide: 2xx = สำเร็จ, 410 = เลิกส่งและปิด endpoint, ครบ maxAttempts = เลิก
+// นอกนั้นรอ Backoff(attempt) แล้วส่งใหม่ (attempt = ครั้งที่เพิ่งส่ง เริ่ม 1)
+func Decide(res Result, attempt, maxAttempts int, now time.Time) Outcome {
+	switch {
+	case res.OK():
+		return Outcome{Status: db.DeliveryDelivered}
+	case res.StatusCode == http.StatusGone:
+		return Outcome{Status: db.DeliveryFailed, DisableEndpoint: true}
+	case attempt >= maxAttempts:
+		return Outcome{Status: db.DeliveryFailed}
+	default:
+		return Outcome{Status: db.DeliveryPending, NextAttempt: now.Add(Backoff(attempt))}
+	}
+}
+
+// Process ดึงงานที่ถึงเวลาแล้วส่งหนึ่งรอบ คืนจำนวนงานที่ทำ
+func Process(ctx context.Context, client *http.Client) (int, error) {
+	timeout := Timeout()
+	// lease นานกว่าเวลาส่งทั้ง batch กันอีก instance หยิบงานเดียวกันไปส่งซ้ำ
+	jobs, err := db.ClaimWebhookDeliveries(ctx, batchSize, 2*timeout+time.Minute)
+	if err != nil || len(jobs) == 0 {
+		return 0, err
+	}
+	maxAttempts := MaxAttempts()
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job db.WebhookJob) {
+			defer wg.Done()
+			c, cancel := context.WithTimeout(ctx, timeout)
+			res := Deliver(c, client, job, time.Now())
+			cancel()
+
+			attempt := job.Attempts + 1
+			o := Decide(res, attempt, maxAttempts, time.Now())
+			status, next := o.Status, o.NextAttempt
+			if err := db.RecordWebhookAttempt(context.WithoutCancel(ctx), db.WebhookAttempt{
+				DeliveryID: job.DeliveryID,
+				Attempt:    attempt,
+				StatusCode: res.StatusCode,
+				Error:      res.Err,
+				Duration:   res.Duration,
+			}, status, next); err != nil {
+				log.Printf("webhook delivery %d: record: %v", job.DeliveryID, err)
+			}
+			if status == db.DeliveryFailed {
+				log.Printf("webhook delivery %d (%s → endpoint %d) failed after %d attempts: %s",
+					job.DeliveryID, job.Event, job.EndpointID, attempt, res.Err)
+			}
+			if o.DisableEndpoint {
+				inactive := false
+				if _, err := db.UpdateWebhookEndpoint(context.WithoutCancel(ctx), job.EndpointID,
+					db.WebhookEndpointPatch{Active: &inactive}); err != nil {
+					log.Printf("webhook endpoint %d: disable: %v", job.EndpointID, err)
+				} else {
+					log.Printf("webhook endpoint %d disabled: receiver answered 410 Gone", job.EndpointID)
+				}
+			}
+		}(job)
+	}
+	wg.Wait()
+	return len(jobs), nil
+}
+
+// Run ส่งคิวทุก PollInterval (งานเต็ม batch ดึงต่อทันที) และล้าง delivery เก่าวันละครั้ง
+// จนกว่า ctx จะถูกยกเลิก
+func Run(ctx context.Context) {
+	client := &http.Client{
+		// ไม่ตาม redirect — ลายเซ็นผูกกับ URL ที่ตั้งไว้ และกันถูกพาไปที่อื่น
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	var lastPrune time.Time
+	t := time.NewTicker(PollInterval())
+	defer t.Stop()
+	for {
+		for {
+			n, err := Process(ctx, client)
+			if err != nil {
+				log.Printf("webhooks: %v", err)
+			}
+			if n < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		if time.Since(lastPrune) > 24*time.Hour {
+			lastPrune = time.Now()
+			if n, err := db.PruneWebhookDeliveries(ctx, time.Now().Add(-Retention())); err != nil {
+				log.Printf("webhooks prune: %v", err)
+			} else if n > 0 {
+				log.Printf("webhooks: pruned %d old deliveries", n)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my-app-backend/internal/db"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	now := time.Unix(1760000000, 0)
+	ts := "1760000000"
+	sig := Sign("s3cret", now, body)
+
+	if !strings.HasPrefix(sig, "sha256=") {
+		t.Fatalf("signature %q has no sha256= prefix", sig)
+	}
+	if !Verify("s3cret", ts, sig, body, now.Add(time.Minute), 5*time.Minute) {
+		t.Error("Verify rejected a valid signature")
+	}
+	if Verify("other", ts, sig, body, now, 5*time.Minute) {
+		t.Error("Verify accepted the wrong secret")
+	}
+	if Verify("s3cret", ts, sig, []byte(`{"event":"pong"}`), now, 5*time.Minute) {
+		t.Error("Verify accepted a changed body")
+	}
+	if Verify("s3cret", ts, sig, body, now.Add(10*time.Minute), 5*time.Minute) {
+		t.Error("Verify accepted a stale timestamp")
+	}
+}
+
+func TestDeliverHeaders(t *testing.T) {
+	job := db.WebhookJob{
+		DeliveryID: 42,
+		EndpointID: 7,
+		Secret:     "s3cret",
+		Event:      EventFeedbackCreated,
+		Payload:    []byte(`{"event":"feedback.created","data":{"id":1}}`),
+	}
+	now := time.Now()
+
+	var got http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	job.URL = srv.URL
+
+	res := Deliver(context.Background(), srv.Client(), job, now)
+	if !res.OK() || res.Err != "" {
+		t.Fatalf("Deliver = %+v, want 2xx", res)
+	}
+	if got.Get(HeaderEvent) != EventFeedbackCreated {
+		t.Errorf("%s = %q", HeaderEvent, got.Get(HeaderEvent))
+	}
+	if got.Get(HeaderDelivery) != "42" {
+		t.Errorf("%s = %q, want 42", HeaderDelivery, got.Get(HeaderDelivery))
+	}
+	if got.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q", got.Get("Content-Type"))
+	}
+	if string(gotBody) != string(job.Payload) {
+		t.Errorf("body = %s", gotBody)
+	}
+	// ปลายทางตรวจลายเซ็นจาก header ที่ได้รับเท่านั้น
+	if !Verify(job.Secret, got.Get(HeaderTimestamp), got.Get(HeaderSignature), gotBody, time.Now(), 5*time.Minute) {
+		t.Errorf("signature %q does not verify", got.Get(HeaderSignature))
+	}
+}
+
+func TestDeliverFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database is down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	res := Deliver(context.Background(), srv.Client(), db.WebhookJob{URL: srv.URL, Payload: []byte(`{}`)}, time.Now())
+	if res.OK() || res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Deliver = %+v, want 503", res)
+	}
+	if !strings.Contains(res.Err, "database is down") {
+		t.Errorf("Err = %q, want the response body", res.Err)
+	}
+}
+
+func TestDeliverUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	res := Deliver(context.Background(), http.DefaultClient, db.WebhookJob{URL: url, Payload: []byte(`{}`)}, time.Now())
+	if res.StatusCode != 0 || res.Err == "" {
+		t.Fatalf("Deliver = %+v, want a connection error", res)
+	}
+	if o := Decide(res, 1, 8, time.Now()); o.Status != db.DeliveryPending {
+		t.Errorf("Decide = %+v, want a retry", o)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		code    int
+		attempt int
+		status  string
+		retry   bool
+		disable bool
+	}{
+		{"delivered", http.StatusOK, 1, db.DeliveryDelivered, false, false},
+		{"server error retries", http.StatusInternalServerError, 1, db.DeliveryPending, true, false},
+		{"client error retries", http.StatusNotFound, 3, db.DeliveryPending, true, false},
+		{"last attempt gives up", http.StatusInternalServerError, 8, db.DeliveryFailed, false, false},
+		{"gone disables endpoint", http.StatusGone, 1, db.DeliveryFailed, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Decide(Result{StatusCode: tt.code}, tt.attempt, 8, now)
+			if o.Status != tt.status || o.DisableEndpoint != tt.disable || o.NextAttempt.IsZero() == tt.retry {
+				t.Fatalf("Decide = %+v", o)
+			}
+			if tt.retry {
+				// ±20% รอบ Backoff ของครั้งนั้น
+				base := backoffBase << (tt.attempt - 1)
+				if d := o.NextAttempt.Sub(now); d < base*8/10 || d > base*12/10 {
+					t.Errorf("retry in %s, want about %s", d, base)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 5: 8 * time.Minute, 30: backoffMax} {
+		for range 20 {
+			if d := Backoff(attempt); d < want*8/10 || d > want*12/10 {
+				t.Fatalf("Backoff(%d) = %s, want %s ±20%%", attempt, d, want)
+			}
+		}
+	}
+}
@@ -3,11 +3,14 @@ import { useNetworkStore } from '../store/useNetworkStore';
 
 
 
-const BASE = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080' // fallback กันลืมตั้ง env
+export const BASE = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080' // fallback กันลืมตั้ง env
+export const APP_VERSION = import.meta.env.VITE_APP_VERSION || 'dev'
 
 const api = axios.create({
   baseURL: BASE,
   timeout: 60000,
+  // ให้ server แนบเวอร์ชันไว้กับ feedback/log (ตั้ง VITE_APP_VERSION ตอน build)
+  headers: { 'X-App-Version': APP_VERSION },
 })
 
 // ---- Interceptors เพื่อนับ pending ----
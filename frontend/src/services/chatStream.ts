@@ -0,0 +1,62 @@
+import { BASE, APP_VERSION } from './api'
+
+// POST /api/chat/stream — อ่าน Server-Sent Events ผ่าน fetch (EventSource ส่ง POST ไม่ได้)
+// event: delta {text} / done {output_text, provider, model, usage, conversation_id} / error {error, status}
+
+export interface ChatDone {
+  output_text: string
+  provider?: string
+  model?: string
+  usage?: { input_tokens: number; output_tokens: number }
+  conversation_id?: number // มีเมื่อส่ง player token (บันทึกประวัติแล้ว)
+}
+
+export class ChatStreamError extends Error {
+  constructor(message: string, public status: number) {
+    super(message)
+  }
+}
+
+export async function streamChat(
+  message: string,
+  onDelta: (text: string) => void,
+  signal?: AbortSignal,
+  conversationId?: number, // คุยต่อในบทสนทนาเดิม
+): Promise<ChatDone> {
+  const res = await fetch(`${BASE}/api/chat/stream`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', 'X-App-Version': APP_VERSION },
+    body: JSON.stringify({ message, conversation_id: conversationId }),
+    signal,
+  })
+  if (!res.ok || !res.body) {
+    // error ก่อนเริ่ม stream ตอบเป็น JSON {error, code}
+    const data = await res.json().catch(() => null)
+    throw new ChatStreamError(data?.error || `HTTP ${res.status}`, res.status)
+  }
+
+  const reader = res.body.pipeThrough(new TextDecoderStream()).getReader()
+  let buf = ''
+  for (;;) {
+    const { value, done } = await reader.read()
+    if (done) break
+    buf += value
+    let i: number
+    while ((i = buf.indexOf('\n\n')) >= 0) {
+      const block = buf.slice(0, i)
+      buf = buf.slice(i + 2)
+      let event = 'message'
+      const data: string[] = []
+      for (const line of block.split('\n')) {
+        if (line.startsWith('event:')) event = line.slice(6).trim()
+        else if (line.startsWith('data:')) data.push(line.slice(5).replace(/^ /, ''))
+      }
+      if (!data.length) continue // ": ping"
+      const payload = JSON.parse(data.join('\n'))
+      if (event === 'delta') onDelta(payload.text ?? '')
+      else if (event === 'done') return payload as ChatDone
+      else if (event === 'error') throw new ChatStreamError(payload.error, payload.status)
+    }
+  }
+  throw new ChatStreamError('stream ended unexpectedly', 0)
+}